@@ -2,12 +2,15 @@ package gotidus
 
 import (
 	"fmt"
+	"strings"
 )
 
 // FullColumnName is a helper function that allows building the name
 // based on the table and column names.
+// As the dialect of the database is unknown, both names are quoted following the SQL standard.
+// The Generator builds the name with its QueryBuilder through QuoteColumnName instead.
 func FullColumnName(tableName, columnName string) string {
-	return fmt.Sprintf("%s.%s", tableName, columnName)
+	return QuoteColumnName(standardQuoter{}, tableName, columnName)
 }
 
 // Anonymizer is the interface for functions that build the query snippet to anonymize a specific column.
@@ -15,8 +18,113 @@ type Anonymizer interface {
 	Build(tableName, columnName string) string
 }
 
+// ColumnAwareAnonymizer is an optional extension of the Anonymizer interface
+// for anonymizers that depend on the metadata of the column, e.g. its data type.
+type ColumnAwareAnonymizer interface {
+	Anonymizer
+	BuildColumn(tableName string, column Column) string
+}
+
+// DialectAwareAnonymizer is an optional extension of the Anonymizer interface
+// for anonymizers embedding column references or values as string literals. The Generator calls
+// BuildDialect with its QueryBuilder, so that both are quoted in the dialect of the database.
+type DialectAwareAnonymizer interface {
+	Anonymizer
+	BuildDialect(quoter Quoter, tableName string, column Column) string
+}
+
+// BuildDialect builds the partial query of the anonymizer for the given column,
+// quoting identifiers and literals with the given Quoter.
+// If the anonymizer does not implement DialectAwareAnonymizer, it behaves like BuildColumn.
+// Anonymizers wrapping other anonymizers should use it to pass the Quoter on.
+func BuildDialect(quoter Quoter, anonymizer Anonymizer, tableName string, column Column) string {
+	if dialectAware, ok := anonymizer.(DialectAwareAnonymizer); ok {
+		return dialectAware.BuildDialect(quoter, tableName, column)
+	}
+
+	return BuildColumn(anonymizer, tableName, column)
+}
+
+// BuildColumn builds the partial query of the anonymizer for the given column.
+// If the anonymizer implements ColumnAwareAnonymizer, the column metadata is passed to BuildColumn.
+// Otherwise, Build is called with the column name.
+// Anonymizers wrapping other anonymizers should use it to pass the metadata on.
+func BuildColumn(anonymizer Anonymizer, tableName string, column Column) string {
+	if columnAware, ok := anonymizer.(ColumnAwareAnonymizer); ok {
+		return columnAware.BuildColumn(tableName, column)
+	}
+
+	return anonymizer.Build(tableName, column.Name)
+}
+
+// WithoutTypeCast wraps the anonymizer to exclude its result from the type preserving casts
+// configured with WithTypePreservingCasts.
+// This is required for anonymizers whose result cannot be cast to the type of the column.
+// Wrapped OmitAnonymizers and PassthroughAnonymizers keep omitting and passing through the column.
+func WithoutTypeCast(anonymizer Anonymizer) Anonymizer {
+	return &uncastAnonymizer{anonymizer: anonymizer}
+}
+
+// uncastAnonymizer is the Anonymizer returned by WithoutTypeCast.
+type uncastAnonymizer struct {
+	anonymizer Anonymizer
+}
+
+// Build returns the partial query of the wrapped anonymizer.
+func (a *uncastAnonymizer) Build(tableName, columnName string) string {
+	return a.anonymizer.Build(tableName, columnName)
+}
+
+// BuildColumn returns the partial query of the wrapped anonymizer for the column.
+func (a *uncastAnonymizer) BuildColumn(tableName string, column Column) string {
+	return BuildColumn(a.anonymizer, tableName, column)
+}
+
+// BuildDialect returns the partial query of the wrapped anonymizer for the column
+// and passes the Quoter on.
+func (a *uncastAnonymizer) BuildDialect(quoter Quoter, tableName string, column Column) string {
+	return BuildDialect(quoter, a.anonymizer, tableName, column)
+}
+
+// unwrapAnonymizer returns the anonymizer wrapped by WithoutTypeCast,
+// so that omitted and passed through columns are recognized regardless of the wrapping.
+func unwrapAnonymizer(anonymizer Anonymizer) Anonymizer {
+	for {
+		uncast, ok := anonymizer.(*uncastAnonymizer)
+		if !ok {
+			return anonymizer
+		}
+
+		anonymizer = uncast.anonymizer
+	}
+}
+
+// isOmitted reports whether the anonymizer omits the column from the view.
+func isOmitted(anonymizer Anonymizer) bool {
+	_, ok := unwrapAnonymizer(anonymizer).(*OmitAnonymizer)
+
+	return ok
+}
+
+// OmitAnonymizer is an Anonymizer interface implementation which marks a column
+// to be omitted from the view entirely.
+// It can be configured like any other Anonymizer, e.g. through column rules or as default anonymizer.
+type OmitAnonymizer struct{}
+
+// NewOmitAnonymizer initializes a new OmitAnonymizer object
+func NewOmitAnonymizer() *OmitAnonymizer {
+	return &OmitAnonymizer{}
+}
+
+// Build returns NULL as partial query.
+// It is not called by the Generator, which leaves omitted columns out of the view.
+func (a *OmitAnonymizer) Build(tableName, columnName string) string {
+	return "NULL"
+}
+
 // NoopAnonymizer is an Anonymizer interface implementation which returns the column value is as.
-// It is also the default anonymizer for every column unless otherwise defined.
+// It is also the default anonymizer for every column unless otherwise defined
+// or a default anonymizer was configured with WithDefaultAnonymizer.
 type NoopAnonymizer struct{}
 
 // NewNoopAnonymizer initializes a new NoopAnonymizer object
@@ -29,13 +137,69 @@ func (a *NoopAnonymizer) Build(tableName, columnName string) string {
 	return FullColumnName(tableName, columnName)
 }
 
+// BuildDialect returns the column name quoted with the given Quoter.
+func (a *NoopAnonymizer) BuildDialect(quoter Quoter, tableName string, column Column) string {
+	return QuoteColumnName(quoter, tableName, column.Name)
+}
+
+// PassthroughAnonymizer is an Anonymizer interface implementation which returns the column value as is.
+// Unlike the NoopAnonymizer, it explicitly marks a column as safe to be exposed
+// and is therefore not replaced by the default anonymizer configured with WithDefaultAnonymizer.
+type PassthroughAnonymizer struct{}
+
+// NewPassthroughAnonymizer initializes a new PassthroughAnonymizer object
+func NewPassthroughAnonymizer() *PassthroughAnonymizer {
+	return &PassthroughAnonymizer{}
+}
+
+// Build returns the column name build from the table and column name
+func (a *PassthroughAnonymizer) Build(tableName, columnName string) string {
+	return FullColumnName(tableName, columnName)
+}
+
+// BuildDialect returns the column name quoted with the given Quoter.
+func (a *PassthroughAnonymizer) BuildDialect(quoter Quoter, tableName string, column Column) string {
+	return QuoteColumnName(quoter, tableName, column.Name)
+}
+
+// ColumnPlaceholder is the placeholder replaced with the full column name
+// in the expression of an ExpressionAnonymizer.
+const ColumnPlaceholder = "{column}"
+
+// ExpressionAnonymizer is an Anonymizer interface implementation that returns an SQL expression
+// based on the column, e.g. to derive the domain of an email address.
+type ExpressionAnonymizer struct {
+	expression string
+}
+
+// NewExpressionAnonymizer initializes a new ExpressionAnonymizer object.
+// Every occurrence of ColumnPlaceholder in the expression is replaced with the full column name.
+// The expression is used as is and must not contain user provided values.
+func NewExpressionAnonymizer(expression string) *ExpressionAnonymizer {
+	return &ExpressionAnonymizer{
+		expression: expression,
+	}
+}
+
+// Build returns the expression with the placeholder replaced by the full column name.
+func (a *ExpressionAnonymizer) Build(tableName, columnName string) string {
+	return strings.ReplaceAll(a.expression, ColumnPlaceholder, FullColumnName(tableName, columnName))
+}
+
+// BuildDialect returns the expression with the placeholder replaced by the column name
+// quoted with the given Quoter.
+func (a *ExpressionAnonymizer) BuildDialect(quoter Quoter, tableName string, column Column) string {
+	return strings.ReplaceAll(a.expression, ColumnPlaceholder, QuoteColumnName(quoter, tableName, column.Name))
+}
+
 // StaticAnonymizer is an Anonymizer interfface implementation that ensures that every row returns the same static value.
 type StaticAnonymizer struct {
 	staticValue string
 	dataType    string
 }
 
-// NewStaticAnonymizer initializes a new StaticAnonymizer object
+// NewStaticAnonymizer initializes a new StaticAnonymizer object.
+// If the data type is empty, the value is cast to the type of the column.
 func NewStaticAnonymizer(staticValue, dataType string) *StaticAnonymizer {
 	return &StaticAnonymizer{
 		staticValue: staticValue,
@@ -44,7 +208,31 @@ func NewStaticAnonymizer(staticValue, dataType string) *StaticAnonymizer {
 }
 
 // Build returns a partial query from the static value and data type given on object initialization.
+// As the dialect of the database is unknown, the static value is quoted following the SQL standard.
+// The Generator calls BuildDialect instead.
 // table and column name are ignored here.
 func (a *StaticAnonymizer) Build(tableName, columnName string) string {
-	return fmt.Sprintf("'%s'::%s", a.staticValue, a.dataType)
+	return a.BuildDialect(standardQuoter{}, tableName, Column{Name: columnName})
+}
+
+// BuildColumn returns the same partial query as Build.
+// If no data type was given on object initialization, the type of the column is used.
+// If the type of the column is unknown as well, the value is not cast.
+func (a *StaticAnonymizer) BuildColumn(tableName string, column Column) string {
+	return a.BuildDialect(standardQuoter{}, tableName, column)
+}
+
+// BuildDialect returns the same partial query as BuildColumn,
+// but quotes the static value and the type of the column with the given Quoter.
+func (a *StaticAnonymizer) BuildDialect(quoter Quoter, tableName string, column Column) string {
+	dataType := a.dataType
+	if dataType == "" {
+		dataType = column.TypeName(quoter)
+	}
+
+	if dataType == "" {
+		return quoter.QuoteLiteral(a.staticValue)
+	}
+
+	return fmt.Sprintf("%s::%s", quoter.QuoteLiteral(a.staticValue), dataType)
 }
@@ -1,21 +1,76 @@
 package gotidus
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/viafintech/gotidus/testutils"
 )
 
 func TestFullColumnName(t *testing.T) {
+	cases := []struct {
+		title      string
+		tableName  string
+		columnName string
+
+		expectedName string
+	}{
+		{
+			title:      "plain names",
+			tableName:  "some_table",
+			columnName: "some_column",
+
+			expectedName: `"some_table"."some_column"`,
+		},
+		{
+			title:      "reserved words",
+			tableName:  "table",
+			columnName: "column",
+
+			expectedName: `"table"."column"`,
+		},
+		{
+			title:      "mixed case and spaces",
+			tableName:  "SomeTable",
+			columnName: "some column",
+
+			expectedName: `"SomeTable"."some column"`,
+		},
+		{
+			title:      "double quote",
+			tableName:  "some_table",
+			columnName: `some"column`,
+
+			expectedName: `"some_table"."some""column"`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			testutils.CompareStrings(
+				FullColumnName(c.tableName, c.columnName),
+				c.expectedName,
+				t,
+			)
+		})
+	}
+}
+
+func TestNoopAnonymizerBuild(t *testing.T) {
+	anonymizer := NewNoopAnonymizer()
+
+	tableName := "some_table"
+	columnName := "some_column"
+
 	testutils.CompareStrings(
-		FullColumnName("table", "column"),
-		"table.column",
+		anonymizer.Build(tableName, columnName),
+		FullColumnName(tableName, columnName),
 		t,
 	)
 }
 
-func TestNoopAnonymizerBuild(t *testing.T) {
-	anonymizer := NewNoopAnonymizer()
+func TestPassthroughAnonymizerBuild(t *testing.T) {
+	anonymizer := NewPassthroughAnonymizer()
 
 	tableName := "some_table"
 	columnName := "some_column"
@@ -39,3 +94,134 @@ func TestStaticAnonymizerBuild(t *testing.T) {
 		t,
 	)
 }
+
+func TestStaticAnonymizerBuildQuotesValue(t *testing.T) {
+	anonymizer := NewStaticAnonymizer("O'Brien", "TEXT")
+
+	testutils.CompareStrings(
+		anonymizer.Build("some_table", "some_column"),
+		"'O''Brien'::TEXT",
+		t,
+	)
+}
+
+func TestStaticAnonymizerBuildWithoutDataType(t *testing.T) {
+	anonymizer := NewStaticAnonymizer("23", "")
+
+	testutils.CompareStrings(anonymizer.Build("some_table", "some_column"), "'23'", t)
+
+	testutils.CompareStrings(
+		anonymizer.BuildColumn("some_table", Column{Name: "some_column"}),
+		"'23'",
+		t,
+	)
+}
+
+func TestStaticAnonymizerBuildColumn(t *testing.T) {
+	column := Column{Name: "some_column", DataType: "integer"}
+
+	testutils.CompareStrings(
+		NewStaticAnonymizer("23", "").BuildColumn("some_table", column),
+		"'23'::integer",
+		t,
+	)
+
+	testutils.CompareStrings(
+		NewStaticAnonymizer("23", "bigint").BuildColumn("some_table", column),
+		"'23'::bigint",
+		t,
+	)
+}
+
+func TestBuildColumn(t *testing.T) {
+	column := Column{Name: "some_column", DataType: "integer"}
+
+	// Anonymizers without column support are called with the column name
+	testutils.CompareStrings(
+		BuildColumn(NewNoopAnonymizer(), "some_table", column),
+		`"some_table"."some_column"`,
+		t,
+	)
+
+	testutils.CompareStrings(
+		BuildColumn(NewStaticAnonymizer("23", ""), "some_table", column),
+		"'23'::integer",
+		t,
+	)
+}
+
+// markingQuoter is a Quoter which marks the identifiers and literals it quoted.
+type markingQuoter struct{}
+
+func (q markingQuoter) QuoteIdentifier(identifier string) string {
+	return fmt.Sprintf("Q(%s)", identifier)
+}
+
+func (q markingQuoter) QuoteLiteral(literal string) string {
+	return fmt.Sprintf("Q'%s'", literal)
+}
+
+func TestBuildDialect(t *testing.T) {
+	column := Column{Name: "some_column", DataType: "integer"}
+
+	testutils.CompareStrings(
+		BuildDialect(markingQuoter{}, NewNoopAnonymizer(), "some_table", column),
+		"Q(some_table).Q(some_column)",
+		t,
+	)
+
+	testutils.CompareStrings(
+		BuildDialect(markingQuoter{}, NewExpressionAnonymizer("LOWER({column})"), "some_table", column),
+		"LOWER(Q(some_table).Q(some_column))",
+		t,
+	)
+
+	// Anonymizers without dialect support are called with the column
+	testutils.CompareStrings(
+		BuildDialect(markingQuoter{}, NewOmitAnonymizer(), "some_table", column),
+		"NULL",
+		t,
+	)
+
+	testutils.CompareStrings(
+		BuildDialect(markingQuoter{}, NewStaticAnonymizer("23", ""), "some_table", Column{
+			Name:     "some_column",
+			DataType: "USER-DEFINED",
+			UDTName:  "mood",
+		}),
+		"Q'23'::Q(mood)",
+		t,
+	)
+
+	testutils.CompareStrings(
+		BuildDialect(markingQuoter{}, NewStaticAnonymizer("23", ""), "some_table", column),
+		"Q'23'::integer",
+		t,
+	)
+
+	testutils.CompareStrings(
+		BuildDialect(markingQuoter{}, WithoutTypeCast(NewStaticAnonymizer("23", "bigint")), "some_table", column),
+		"Q'23'::bigint",
+		t,
+	)
+}
+
+func TestWithoutTypeCast(t *testing.T) {
+	anonymizer := WithoutTypeCast(NewStaticAnonymizer("23", ""))
+
+	testutils.CompareStrings(
+		BuildColumn(anonymizer, "some_table", Column{Name: "some_column", DataType: "integer"}),
+		"'23'::integer",
+		t,
+	)
+}
+
+func TestExpressionAnonymizerBuild(t *testing.T) {
+	anonymizer := NewExpressionAnonymizer("SPLIT_PART({column}, '@', 2) || {column}")
+
+	testutils.CompareStrings(
+		anonymizer.Build("users", "user"),
+		`SPLIT_PART("users"."user", '@', 2) || "users"."user"`,
+		t,
+	)
+}
@@ -0,0 +1,157 @@
+package gotidus
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// AuditColumn identifies a column of a table.
+// The table name is qualified with its schema in the same way as the TableName of a Statement.
+type AuditColumn struct {
+	Table  string
+	Column string
+}
+
+// String joins the table and column name to <table>.<column>.
+func (c AuditColumn) String() string {
+	return fmt.Sprintf("%s.%s", c.Table, c.Column)
+}
+
+// AuditReport lists the columns that are exposed as they are, because no Anonymizer
+// was configured for them, and compares them against a baseline of acknowledged columns.
+// Unconfigured contains every column served by the implicit NoopAnonymizer.
+// Violations contains the unconfigured columns missing in the baseline and
+// Stale the baseline entries that are no longer unconfigured.
+type AuditReport struct {
+	Unconfigured []AuditColumn
+	Violations   []AuditColumn
+	Stale        []AuditColumn
+}
+
+// WriteBaseline writes the unconfigured columns of the report in the format read by ReadAuditBaseline.
+// It can be used to create the initial baseline or to acknowledge the current violations.
+func (r *AuditReport) WriteBaseline(w io.Writer) error {
+	for _, column := range r.Unconfigured {
+		entry := fmt.Sprintf("%s\t%s", strconv.Quote(column.Table), strconv.Quote(column.Column))
+
+		if _, err := fmt.Fprintln(w, entry); err != nil {
+			return fmt.Errorf("Failed to write baseline: %+v", err)
+		}
+	}
+
+	return nil
+}
+
+// ReadAuditBaseline reads a baseline of acknowledged unconfigured columns.
+// The baseline contains one entry per line with the table and column names
+// as double quoted Go string literals separated by a tab, so that names containing
+// dots or line breaks are read unambiguously. Empty lines and lines starting with # are ignored.
+func ReadAuditBaseline(r io.Reader) ([]AuditColumn, error) {
+	baseline := make([]AuditColumn, 0)
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		column, ok := parseBaselineEntry(line)
+		if !ok {
+			return nil, fmt.Errorf("Invalid baseline entry '%s' in line %d", line, lineNumber)
+		}
+
+		baseline = append(baseline, column)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Failed to read baseline: %+v", err)
+	}
+
+	return baseline, nil
+}
+
+// parseBaselineEntry parses the quoted table and column names of a baseline entry
+// and reports whether the entry is valid.
+func parseBaselineEntry(line string) (AuditColumn, bool) {
+	fields := strings.Split(line, "\t")
+	if len(fields) != 2 {
+		return AuditColumn{}, false
+	}
+
+	table, err := strconv.Unquote(fields[0])
+	if err != nil || !strings.HasPrefix(fields[0], `"`) {
+		return AuditColumn{}, false
+	}
+
+	column, err := strconv.Unquote(fields[1])
+	if err != nil || !strings.HasPrefix(fields[1], `"`) {
+		return AuditColumn{}, false
+	}
+
+	return AuditColumn{Table: table, Column: column}, true
+}
+
+// Audit reads the tables and columns from the database and reports every column
+// served by the implicit NoopAnonymizer. Columns missing in the given baseline are
+// reported as violations, which allows failing a CI build when a migration adds
+// a column nobody has reviewed.
+func (g *Generator) Audit(ctx context.Context, db Querier, baseline []AuditColumn) (*AuditReport, error) {
+	snapshot, err := g.Snapshot(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.AuditSnapshot(snapshot, baseline), nil
+}
+
+// AuditSnapshot is the equivalent of Audit for the tables and columns of the given Snapshot.
+func (g *Generator) AuditSnapshot(snapshot *Snapshot, baseline []AuditColumn) *AuditReport {
+	report := &AuditReport{
+		Unconfigured: make([]AuditColumn, 0),
+		Violations:   make([]AuditColumn, 0),
+		Stale:        make([]AuditColumn, 0),
+	}
+
+	acknowledged := make(map[AuditColumn]bool, len(baseline))
+	for _, column := range baseline {
+		acknowledged[column] = false
+	}
+
+	for _, snapshotTable := range snapshot.Tables {
+		if !g.includeSnapshotTable(snapshotTable) {
+			continue
+		}
+
+		tableName := g.sourceTable(snapshotTable).String()
+
+		for _, column := range snapshotTable.Columns {
+			if g.resolveAnonymizer(snapshotTable, column).source != AnonymizerSourceNone {
+				continue
+			}
+
+			auditColumn := AuditColumn{Table: tableName, Column: column.Name}
+
+			report.Unconfigured = append(report.Unconfigured, auditColumn)
+
+			if _, ok := acknowledged[auditColumn]; !ok {
+				report.Violations = append(report.Violations, auditColumn)
+				continue
+			}
+
+			acknowledged[auditColumn] = true
+		}
+	}
+
+	for _, column := range baseline {
+		if !acknowledged[column] {
+			report.Stale = append(report.Stale, column)
+		}
+	}
+
+	return report
+}
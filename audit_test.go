@@ -0,0 +1,195 @@
+package gotidus
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/viafintech/gotidus/testutils"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestReadAuditBaseline(t *testing.T) {
+	cases := []struct {
+		title    string
+		baseline string
+
+		expectedBaseline []AuditColumn
+		expectedError    error
+	}{
+		{
+			title: "entries with comments and empty lines",
+			baseline: "# reviewed 2024-01-01\n" +
+				"\"foo\"\t\"id\"\n" +
+				"\n" +
+				"\"billing.users\"\t\"created_at\"\n",
+
+			expectedBaseline: []AuditColumn{
+				{Table: "foo", Column: "id"},
+				{Table: "billing.users", Column: "created_at"},
+			},
+		},
+		{
+			title:    "entry without column",
+			baseline: "\"foo\"\t\"id\"\n\"foo\"\n",
+
+			expectedError: errors.New(`Invalid baseline entry '"foo"' in line 2`),
+		},
+		{
+			title:    "unquoted entry",
+			baseline: "foo\tid\n",
+
+			expectedError: errors.New("Invalid baseline entry 'foo\tid' in line 1"),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			baseline, err := ReadAuditBaseline(strings.NewReader(c.baseline))
+
+			testutils.CompareStructs(err, c.expectedError, t)
+			testutils.CompareStructs(baseline, c.expectedBaseline, t)
+		})
+	}
+}
+
+func TestAuditReportWriteBaseline(t *testing.T) {
+	report := &AuditReport{
+		Unconfigured: []AuditColumn{
+			{Table: "foo", Column: "id"},
+			{Table: "foo", Column: "bar"},
+		},
+	}
+
+	var buf bytes.Buffer
+
+	if err := report.WriteBaseline(&buf); err != nil {
+		t.Fatalf("Failed to write baseline: %+v", err)
+	}
+
+	testutils.CompareStrings(buf.String(), "\"foo\"\t\"id\"\n\"foo\"\t\"bar\"\n", t)
+}
+
+func TestAuditReportWriteBaselineRoundTrip(t *testing.T) {
+	report := &AuditReport{
+		Unconfigured: []AuditColumn{
+			{Table: "billing.users", Column: "created_at"},
+			{Table: "foo", Column: "bar.baz"},
+			{Table: "foo", Column: "multi\nline"},
+			{Table: "foo", Column: "tab\tand \"quote\""},
+		},
+	}
+
+	var buf bytes.Buffer
+
+	if err := report.WriteBaseline(&buf); err != nil {
+		t.Fatalf("Failed to write baseline: %+v", err)
+	}
+
+	baseline, err := ReadAuditBaseline(&buf)
+	if err != nil {
+		t.Fatalf("Failed to read baseline: %+v", err)
+	}
+
+	testutils.CompareStructs(baseline, report.Unconfigured, t)
+}
+
+func TestGeneratorAudit(t *testing.T) {
+	queryBuilder := &mockQueryBuilder{}
+
+	db, dbMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to initialize DB mock")
+	}
+
+	tableRows := sqlmock.NewRows([]string{"schemaname", "tablename"})
+	tableRows.AddRow("public", "foo")
+
+	dbMock.
+		ExpectQuery(queryBuilder.ListTablesQuery()).
+		WithArgs("").
+		WillReturnRows(tableRows)
+
+	columnRows := newColumnRows()
+	columnRows.AddRow("id", "integer", 1, false, nil, nil, nil, nil, nil, nil)
+	columnRows.AddRow("iban", "text", 2, false, nil, nil, nil, nil, nil, nil)
+	columnRows.AddRow("email", "text", 3, false, nil, nil, nil, nil, nil, nil)
+	columnRows.AddRow("created_at", "timestamp", 4, false, nil, nil, nil, nil, nil, nil)
+
+	dbMock.
+		ExpectQuery(queryBuilder.ListColumnsQuery()).
+		WithArgs("public", "foo").
+		WillReturnRows(columnRows)
+
+	generator := NewGenerator(queryBuilder)
+	generator.AddTable(
+		"foo",
+		NewTable().
+			Allow("created_at").
+			AddAnonymizer("iban", NewStaticAnonymizer("iban", "TEXT")),
+	)
+
+	report, err := generator.Audit(
+		context.Background(),
+		db,
+		[]AuditColumn{
+			{Table: "foo", Column: "id"},
+			{Table: "foo", Column: "removed"},
+		},
+	)
+	if err != nil {
+		t.Fatalf("Failed to audit: %+v", err)
+	}
+
+	expectedReport := &AuditReport{
+		Unconfigured: []AuditColumn{
+			{Table: "foo", Column: "id"},
+			{Table: "foo", Column: "email"},
+		},
+		Violations: []AuditColumn{
+			{Table: "foo", Column: "email"},
+		},
+		Stale: []AuditColumn{
+			{Table: "foo", Column: "removed"},
+		},
+	}
+
+	testutils.CompareStructs(report, expectedReport, t)
+
+	if err := dbMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Did not execute expected queries: %+v", err)
+	}
+}
+
+func TestGeneratorAuditSnapshotWithDefaultAnonymizer(t *testing.T) {
+	snapshot := &Snapshot{
+		Tables: []SnapshotTable{
+			{
+				Schema: "public",
+				Name:   "foo",
+				Columns: []Column{
+					{Name: "id", DataType: "integer", OrdinalPosition: 1},
+				},
+			},
+		},
+	}
+
+	generator := NewGenerator(
+		&mockQueryBuilder{},
+		WithDefaultAnonymizer(NewStaticAnonymizer("redacted", "TEXT")),
+	)
+
+	report := generator.AuditSnapshot(snapshot, nil)
+
+	testutils.CompareStructs(
+		report,
+		&AuditReport{
+			Unconfigured: []AuditColumn{},
+			Violations:   []AuditColumn{},
+			Stale:        []AuditColumn{},
+		},
+		t,
+	)
+}
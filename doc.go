@@ -1,12 +1,18 @@
 /*
 Package gotidus is an SQL anonymization view builder for go
 
+Methods changing the database, e.g. CreateViewsContext, SyncViews or Plan.Apply,
+execute all their statements within a single transaction, which is rolled back
+on failure or cancellation of the context. If the given Querier is a *sql.Tx already,
+the statements run within it and committing or rolling back is left to the caller.
+
 Example:
 
   fooTable := gotidus.NewTable()
   // Define columns on the table to anonymize in a specific way.
   // Other columns will just contain their normal value.
-  // Note: Any column defined but not actually in the table will be ignored.
+  // Note: Any column defined but not actually in the table will be ignored,
+  // unless the generator is created with gotidus.WithStrictConfig().
   fooTable.AddAnonymizer(
       "bar",
       gotidus.NewStaticAnonymizer("staticValue", "TEXT"),
@@ -16,7 +22,8 @@ Example:
   // Tables that are not supposed to be anonymized specifically,
   // do not have to be defined.
   //
-  // Note: Any table defined but not actually in the database will be ignored.
+  // Note: Any table defined but not actually in the database will be ignored,
+  // unless the generator is created with gotidus.WithStrictConfig().
   generator.AddTable("foo", fooTable)
 
   // Clear existing views
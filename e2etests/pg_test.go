@@ -31,7 +31,10 @@ func TestPostgres(t *testing.T) {
 	cases := []struct {
 		title               string
 		setupQueries        []string
+		queryBuilderOptions []postgres.QueryBuilderOption
+		generatorOptions    []gotidus.GeneratorOption
 		anonymizationConfig map[string]*gotidus.Table
+		typeRules           []*gotidus.TypeRule
 		queryChecks         []queryCheck
 	}{
 		{
@@ -395,6 +398,408 @@ func TestPostgres(t *testing.T) {
 				},
 			},
 		},
+		{
+			title: "Identifier quoting: mixed case and reserved words",
+			setupQueries: []string{
+				`CREATE TABLE "order" ("user" TEXT, "CamelCase" TEXT)`,
+				`INSERT INTO "order" ("user", "CamelCase") VALUES ('some_user', 'value_to_be_overwritten')`,
+			},
+			anonymizationConfig: map[string]*gotidus.Table{
+				"order": gotidus.NewTable().
+					AddAnonymizer("CamelCase", postgres.NewOverlayAnonymizer("X", 1, 5)),
+			},
+			queryChecks: []queryCheck{
+				{
+					Query: `SELECT "user" || ':' || "CamelCase" FROM order_anonymized`,
+					ExpectationFunc: func(row *sql.Row, t *testing.T) {
+						expectedStr := "some_user:XXXXX_to_be_overwritten"
+						var str string
+
+						err := row.Scan(&str)
+						if err != nil {
+							t.Errorf("Failed to retrieve value from check query: %+v", err)
+						}
+
+						testutils.CompareStrings(str, expectedStr, t)
+					},
+				},
+			},
+		},
+		{
+			title: "Target schema: create views in a dedicated schema",
+			setupQueries: []string{
+				"CREATE SCHEMA billing",
+				"CREATE SCHEMA anonymized",
+				"CREATE TABLE test_table (test_column TEXT)",
+				"CREATE TABLE billing.test_table (iban TEXT)",
+				"INSERT INTO test_table (test_column) VALUES ('public_value')",
+				"INSERT INTO billing.test_table (iban) VALUES ('DE02120300000000202051')",
+			},
+			generatorOptions: []gotidus.GeneratorOption{
+				gotidus.WithSourceSchemas("billing"),
+				gotidus.WithTargetSchema("anonymized"),
+			},
+			anonymizationConfig: map[string]*gotidus.Table{
+				"billing.test_table": gotidus.NewTable().
+					AddAnonymizer("iban", gotidus.NewStaticAnonymizer("static_value", "TEXT")),
+			},
+			queryChecks: []queryCheck{
+				{
+					Query: "SELECT iban FROM anonymized.test_table",
+					ExpectationFunc: func(row *sql.Row, t *testing.T) {
+						expectedStr := "static_value"
+						var str string
+
+						err := row.Scan(&str)
+						if err != nil {
+							t.Errorf("Failed to retrieve value from check query: %+v", err)
+						}
+
+						testutils.CompareStrings(str, expectedStr, t)
+					},
+				},
+			},
+		},
+		{
+			title: "Type preserving casts: keep the column types of the table",
+			setupQueries: []string{
+				"CREATE TABLE test_table (email VARCHAR(64), amount NUMERIC(10, 2))",
+				"INSERT INTO test_table (email, amount) VALUES ('foo@example.com', 12.34)",
+			},
+			generatorOptions: []gotidus.GeneratorOption{
+				gotidus.WithTypePreservingCasts(),
+			},
+			anonymizationConfig: map[string]*gotidus.Table{
+				"test_table": gotidus.NewTable().
+					AddAnonymizer("email", postgres.NewEmailAnonymizer()).
+					AddAnonymizer("amount", postgres.NewNullAnonymizer()),
+			},
+			queryChecks: []queryCheck{
+				{
+					Query: `
+						SELECT string_agg(
+							pg_catalog.format_type(atttypid, atttypmod), ', ' ORDER BY attnum
+						)
+						FROM pg_catalog.pg_attribute
+						WHERE attrelid = 'test_table_anonymized'::regclass AND attnum > 0`,
+					ExpectationFunc: func(row *sql.Row, t *testing.T) {
+						expectedStr := "character varying(64), numeric(10,2)"
+						var str string
+
+						err := row.Scan(&str)
+						if err != nil {
+							t.Errorf("Failed to retrieve value from check query: %+v", err)
+						}
+
+						testutils.CompareStrings(str, expectedStr, t)
+					},
+				},
+			},
+		},
+		{
+			title: "Type rules: anonymize columns by domain and type category",
+			setupQueries: []string{
+				"CREATE DOMAIN email_address AS TEXT",
+				"CREATE TABLE test_table (email email_address, ip INET)",
+				"INSERT INTO test_table (email, ip) VALUES ('foo@example.com', '192.168.1.1')",
+			},
+			typeRules: []*gotidus.TypeRule{
+				gotidus.NewTypeRule(
+					gotidus.MatchDomain("email_address"),
+					gotidus.NewStaticAnonymizer("static_value", "TEXT"),
+				),
+				gotidus.NewTypeRule(
+					gotidus.MatchTypeCategory("I"),
+					gotidus.NewStaticAnonymizer("0.0.0.0", "INET"),
+				),
+			},
+			queryChecks: []queryCheck{
+				{
+					Query: "SELECT email || ' ' || ip::text FROM test_table_anonymized",
+					ExpectationFunc: func(row *sql.Row, t *testing.T) {
+						expectedStr := "static_value 0.0.0.0/32"
+						var str string
+
+						err := row.Scan(&str)
+						if err != nil {
+							t.Errorf("Failed to retrieve value from check query: %+v", err)
+						}
+
+						testutils.CompareStrings(str, expectedStr, t)
+					},
+				},
+			},
+		},
+		{
+			title: "Row filters: expose only matching rows",
+			setupQueries: []string{
+				"CREATE TABLE test_table (email TEXT, deleted_at TIMESTAMP)",
+				"INSERT INTO test_table (email) VALUES ('foo@example.com')",
+				"INSERT INTO test_table (email) VALUES ('test@internal.example')",
+				"INSERT INTO test_table (email, deleted_at) VALUES ('bar@example.com', NOW())",
+			},
+			anonymizationConfig: map[string]*gotidus.Table{
+				"test_table": gotidus.NewTable().
+					WithRowFilter(
+						postgres.NewConditionRowFilter("email", "NOT LIKE", "%@internal.example", "TEXT"),
+						gotidus.NewSQLRowFilter("deleted_at IS NULL"),
+					),
+			},
+			queryChecks: []queryCheck{
+				{
+					Query: "SELECT string_agg(email, ',') FROM test_table_anonymized",
+					ExpectationFunc: func(row *sql.Row, t *testing.T) {
+						expectedStr := "foo@example.com"
+						var str string
+
+						err := row.Scan(&str)
+						if err != nil {
+							t.Errorf("Failed to retrieve value from check query: %+v", err)
+						}
+
+						testutils.CompareStrings(str, expectedStr, t)
+					},
+				},
+			},
+		},
+		{
+			title: "Sampling: keep related rows of a deterministic sample",
+			setupQueries: []string{
+				"CREATE TABLE customers (id INTEGER)",
+				"CREATE TABLE orders (customer_id INTEGER)",
+				"INSERT INTO customers (id) SELECT generate_series(1, 1000)",
+				"INSERT INTO orders (customer_id) SELECT generate_series(1, 1000)",
+			},
+			anonymizationConfig: map[string]*gotidus.Table{
+				"customers": gotidus.NewTable().WithSampling(postgres.NewHashSampler("id", 10)),
+				"orders":    gotidus.NewTable().WithSampling(postgres.NewHashSampler("customer_id", 10)),
+			},
+			queryChecks: []queryCheck{
+				{
+					Query: `
+						SELECT
+							(SELECT COUNT(*) FROM customers_anonymized) BETWEEN 50 AND 150
+							AND NOT EXISTS (
+								SELECT id FROM customers_anonymized
+								EXCEPT
+								SELECT customer_id FROM orders_anonymized
+							)`,
+					ExpectationFunc: func(row *sql.Row, t *testing.T) {
+						var sampled bool
+
+						err := row.Scan(&sampled)
+						if err != nil {
+							t.Errorf("Failed to retrieve value from check query: %+v", err)
+						}
+
+						if !sampled {
+							t.Errorf("Expected a sample of related rows")
+						}
+					},
+				},
+			},
+		},
+		{
+			title: "Computed columns: expose derived values",
+			setupQueries: []string{
+				"CREATE TABLE test_table (email TEXT)",
+				"INSERT INTO test_table (email) VALUES ('foo@example.com')",
+			},
+			anonymizationConfig: map[string]*gotidus.Table{
+				"test_table": gotidus.NewTable().
+					OmitColumn("email").
+					AddComputedColumn(
+						"email_domain",
+						"email",
+						gotidus.NewExpressionAnonymizer("SPLIT_PART({column}, '@', 2)"),
+					),
+			},
+			queryChecks: []queryCheck{
+				{
+					Query: "SELECT email_domain FROM test_table_anonymized",
+					ExpectationFunc: func(row *sql.Row, t *testing.T) {
+						expectedStr := "example.com"
+						var str string
+
+						err := row.Scan(&str)
+						if err != nil {
+							t.Errorf("Failed to retrieve value from check query: %+v", err)
+						}
+
+						testutils.CompareStrings(str, expectedStr, t)
+					},
+				},
+			},
+		},
+		{
+			title: "Structure only: expose the columns without rows",
+			setupQueries: []string{
+				"CREATE TABLE test_table (token TEXT)",
+				"INSERT INTO test_table (token) VALUES ('secret')",
+			},
+			anonymizationConfig: map[string]*gotidus.Table{
+				"test_table": gotidus.NewTable().StructureOnly(),
+			},
+			queryChecks: []queryCheck{
+				{
+					Query: "SELECT COUNT(token) FROM test_table_anonymized",
+					ExpectationFunc: func(row *sql.Row, t *testing.T) {
+						var count int
+
+						err := row.Scan(&count)
+						if err != nil {
+							t.Errorf("Failed to retrieve value from check query: %+v", err)
+						}
+
+						testutils.CompareStructs(count, 0, t)
+					},
+				},
+			},
+		},
+		{
+			title: "Unmask role: show original values to members of the role",
+			setupQueries: []string{
+				"CREATE TABLE test_table (support_email TEXT)",
+				"INSERT INTO test_table (support_email) VALUES ('foo@example.com')",
+			},
+			generatorOptions: []gotidus.GeneratorOption{
+				gotidus.WithUnmaskRole("pg_monitor"),
+			},
+			anonymizationConfig: map[string]*gotidus.Table{
+				"test_table": gotidus.NewTable().
+					AddAnonymizer("support_email", gotidus.NewStaticAnonymizer("static_value", "TEXT")),
+			},
+			queryChecks: []queryCheck{
+				{
+					// The superuser running the tests is a member of every role.
+					Query: "SELECT support_email FROM test_table_anonymized",
+					ExpectationFunc: func(row *sql.Row, t *testing.T) {
+						expectedStr := "foo@example.com"
+						var str string
+
+						err := row.Scan(&str)
+						if err != nil {
+							t.Errorf("Failed to retrieve value from check query: %+v", err)
+						}
+
+						testutils.CompareStrings(str, expectedStr, t)
+					},
+				},
+			},
+		},
+		{
+			title: "Grantees: grant select on views and revoke it on tables",
+			setupQueries: []string{
+				"DO $$ BEGIN CREATE ROLE gotidus_analytics; EXCEPTION WHEN duplicate_object THEN NULL; END $$",
+				"DO $$ BEGIN CREATE ROLE gotidus_legacy; EXCEPTION WHEN duplicate_object THEN NULL; END $$",
+				"CREATE TABLE test_table (id INTEGER, email TEXT)",
+				"GRANT SELECT ON test_table TO gotidus_analytics",
+				"CREATE VIEW test_table_anonymized AS SELECT id FROM test_table",
+				"GRANT SELECT ON test_table_anonymized TO gotidus_legacy",
+				"GRANT SELECT ON test_table_anonymized TO PUBLIC",
+			},
+			generatorOptions: []gotidus.GeneratorOption{
+				gotidus.WithGrantees("gotidus_analytics"),
+				gotidus.WithRevokeBaseTables(),
+			},
+			anonymizationConfig: map[string]*gotidus.Table{
+				"test_table": gotidus.NewTable().
+					AddAnonymizer("email", gotidus.NewStaticAnonymizer("static_value", "TEXT")),
+			},
+			queryChecks: []queryCheck{
+				{
+					Query: `
+						SELECT
+							has_table_privilege('gotidus_analytics', 'test_table_anonymized', 'SELECT'),
+							has_table_privilege('gotidus_legacy', 'test_table_anonymized', 'SELECT'),
+							has_table_privilege('public', 'test_table_anonymized', 'SELECT'),
+							has_table_privilege('gotidus_analytics', 'test_table', 'SELECT')`,
+					ExpectationFunc: func(row *sql.Row, t *testing.T) {
+						var analyticsView, legacyView, publicView, analyticsTable bool
+
+						err := row.Scan(&analyticsView, &legacyView, &publicView, &analyticsTable)
+						if err != nil {
+							t.Errorf("Failed to retrieve value from check query: %+v", err)
+						}
+
+						testutils.CompareStructs(
+							[]bool{analyticsView, legacyView, publicView, analyticsTable},
+							[]bool{true, false, false, false},
+							t,
+						)
+					},
+				},
+			},
+		},
+		{
+			title: "Security options: create views as security barrier and security invoker",
+			setupQueries: []string{
+				"CREATE TABLE test_table (id INTEGER, deleted BOOLEAN)",
+			},
+			queryBuilderOptions: []postgres.QueryBuilderOption{
+				postgres.WithSecurityBarrier(),
+				postgres.WithSecurityInvoker(),
+			},
+			anonymizationConfig: map[string]*gotidus.Table{
+				"test_table": gotidus.NewTable().WithRowFilter(gotidus.NewSQLRowFilter("NOT deleted")),
+			},
+			queryChecks: []queryCheck{
+				{
+					Query: "SELECT array_to_string(reloptions, ',') FROM pg_class WHERE relname = 'test_table_anonymized'",
+					ExpectationFunc: func(row *sql.Row, t *testing.T) {
+						expectedStr := "security_barrier=true,security_invoker=true"
+						var str string
+
+						err := row.Scan(&str)
+						if err != nil {
+							t.Errorf("Failed to retrieve value from check query: %+v", err)
+						}
+
+						testutils.CompareStrings(str, expectedStr, t)
+					},
+				},
+			},
+		},
+		{
+			title: "Materialized views: store the anonymized data with a unique index",
+			setupQueries: []string{
+				"CREATE TABLE test_table (id INTEGER, email TEXT)",
+				"INSERT INTO test_table (id, email) VALUES (1, 'foo@example.com')",
+			},
+			generatorOptions: []gotidus.GeneratorOption{
+				gotidus.WithMaterializedViews(),
+			},
+			anonymizationConfig: map[string]*gotidus.Table{
+				"test_table": gotidus.NewTable().
+					AddAnonymizer("email", gotidus.NewStaticAnonymizer("static_value", "TEXT")).
+					WithUniqueKey("id"),
+			},
+			queryChecks: []queryCheck{
+				{
+					Query: `
+						SELECT
+							(SELECT COUNT(*) FROM pg_matviews WHERE matviewname = 'test_table_anonymized'),
+							(SELECT COUNT(*) FROM pg_indexes WHERE indexname = 'test_table_anonymized_key'),
+							(SELECT email FROM test_table_anonymized WHERE id = 1)`,
+					ExpectationFunc: func(row *sql.Row, t *testing.T) {
+						var (
+							matviews int
+							indexes  int
+							email    string
+						)
+
+						err := row.Scan(&matviews, &indexes, &email)
+						if err != nil {
+							t.Errorf("Failed to retrieve value from check query: %+v", err)
+						}
+
+						testutils.CompareStructs(matviews, 1, t)
+						testutils.CompareStructs(indexes, 1, t)
+						testutils.CompareStrings(email, "static_value", t)
+					},
+				},
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -406,13 +811,20 @@ func TestPostgres(t *testing.T) {
 				}
 			}
 
-			generator := gotidus.NewGenerator(postgres.NewQueryBuilder())
+			generator := gotidus.NewGenerator(
+				postgres.NewQueryBuilder(c.queryBuilderOptions...),
+				c.generatorOptions...,
+			)
 			if c.anonymizationConfig != nil {
 				for tableName, table := range c.anonymizationConfig {
 					generator.AddTable(tableName, table)
 				}
 			}
 
+			for _, rule := range c.typeRules {
+				generator.AddTypeRule(rule)
+			}
+
 			err = generator.CreateViews(db)
 			if err != nil {
 				t.Errorf("Failed to create views: %+v", err)
@@ -438,6 +850,8 @@ func TestPostgres(t *testing.T) {
 
 func resetPGDB(db *sql.DB, t *testing.T) {
 	queries := []string{
+		"DROP SCHEMA IF EXISTS billing CASCADE;",
+		"DROP SCHEMA IF EXISTS anonymized CASCADE;",
 		"DROP SCHEMA public CASCADE;",
 		"CREATE SCHEMA public;",
 		"GRANT ALL ON SCHEMA public TO postgres;",
@@ -1,20 +1,94 @@
 package gotidus
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 )
 
 // QueryBuilder is the interface used to implement support for different databases.
 type QueryBuilder interface {
+	// ListViewsQuery has to return the name of each view.
+	// QueryBuilders implementing MaterializedViewQueryBuilder have to return
+	// whether the view is materialized as second column.
 	ListViewsQuery() string
 	DropViewQuery(viewName string) string
 
 	ListTablesQuery() string
 
+	// ListColumnsQuery has to return the name, data type, ordinal position, nullability,
+	// default value, maximum character length, underlying type name,
+	// full type definition including modifiers, type category and domain name of each column.
 	ListColumnsQuery() string
 
 	CreateViewQuery(viewName string, tableName string, columns []string) string
+
+	QuoteIdentifier(identifier string) string
+	QuoteLiteral(literal string) string
+}
+
+// ServerVersionChecker is an optional interface for QueryBuilders whose queries
+// depend on the version of the database server.
+// If the QueryBuilder implements it, the server version is checked before views are created.
+type ServerVersionChecker interface {
+	// ServerVersionQuery has to return the version of the server as a single value.
+	// An empty query disables the check.
+	ServerVersionQuery() string
+	CheckServerVersion(version string) error
+}
+
+// SyncQueryBuilder is an optional interface for QueryBuilders supporting SyncViews and PlanSync,
+// which store the fingerprints of the views as comments.
+type SyncQueryBuilder interface {
+	// ListViewFingerprintsQuery has to return the name and comment of each view.
+	// QueryBuilders implementing MaterializedViewQueryBuilder have to return
+	// whether the view is materialized as third column.
+	ListViewFingerprintsQuery() string
+	CommentViewQuery(viewName string, comment string) string
+}
+
+// CastQueryBuilder is an optional interface for QueryBuilders supporting WithTypePreservingCasts.
+type CastQueryBuilder interface {
+	CastQuery(expression string, typeName string) string
+}
+
+// FilteredViewQueryBuilder is an optional interface for QueryBuilders supporting
+// row filters, sampling and structure only tables.
+type FilteredViewQueryBuilder interface {
+	CreateFilteredViewQuery(viewName string, tableName string, columns []string, conditions ...string) string
+	NoRowsCondition() string
+}
+
+// UnmaskQueryBuilder is an optional interface for QueryBuilders supporting unmask roles.
+type UnmaskQueryBuilder interface {
+	UnmaskQuery(role string, original string, anonymized string) string
+}
+
+// GrantQueryBuilder is an optional interface for QueryBuilders supporting WithGrantees.
+type GrantQueryBuilder interface {
+	// ListViewGranteesQuery has to return the name of each view along with every role,
+	// apart from the owner, having been granted SELECT on it.
+	// Grants to all roles have to be returned as PublicGrantee.
+	ListViewGranteesQuery() string
+	GrantSelectQuery(objectName string, roles []string) string
+	RevokeSelectQuery(objectName string, roles []string) string
+}
+
+// MaterializedViewQueryBuilder is an optional interface for QueryBuilders supporting
+// WithMaterializedViews and RefreshViews.
+type MaterializedViewQueryBuilder interface {
+	DropMaterializedViewQuery(viewName string) string
+	CommentMaterializedViewQuery(viewName string, comment string) string
+	CreateMaterializedViewQuery(viewName string, tableName string, columns []string, conditions ...string) string
+	CreateUniqueIndexQuery(indexName string, viewName string, columns []string) string
+	RefreshMaterializedViewQuery(viewName string, concurrently bool) string
+}
+
+// unsupportedError describes a feature that is configured but not supported by the QueryBuilder,
+// as it does not implement the respective optional interface.
+func unsupportedError(interfaceName string, feature string) error {
+	return fmt.Errorf("QueryBuilder does not implement gotidus.%s required for %s", interfaceName, feature)
 }
 
 // DefaultViewPostfix defines the postfix given to views to distinguish them from the table names.
@@ -39,12 +113,29 @@ func NewGenerator(queryBuilder QueryBuilder, options ...GeneratorOption) *Genera
 // Generator is the type orchestrating the view clearing and creation,
 // based on the table config.
 type Generator struct {
-	queryBuilder QueryBuilder
-	tables       map[string]*Table
-	viewPostfix  string
+	queryBuilder  QueryBuilder
+	tables        map[string]*Table
+	viewPostfix   string
+	sourceSchemas []string
+	targetSchema  string
+	strictConfig  bool
+
+	columnRules       []*ColumnRule
+	typeRules         []*TypeRule
+	defaultAnonymizer Anonymizer
+	typeCasts         bool
+	includedTables    []NameMatcher
+	excludedTables    []NameMatcher
+	structureOnly     []NameMatcher
+	unmaskRole        string
+	grantees          []string
+	revokeBaseTables  bool
+	materializedViews bool
 }
 
 // AddTable adds a Table configuration to the generator with the given name.
+// The name can be qualified with a schema as <schema>.<table> to only apply to the table
+// in that schema. Otherwise it applies to tables of the given name in every source schema.
 // If this function is called again with the same name, it will overwrite the existing table.
 func (g *Generator) AddTable(name string, table *Table) *Generator {
 	g.tables[name] = table
@@ -64,24 +155,74 @@ func (g *Generator) GetTable(name string) *Table {
 	return NewTable()
 }
 
-func (g *Generator) loopExistingViews(db *sql.DB, viewFunc func(viewName string) error) error {
-	rows, err := db.Query(
-		g.queryBuilder.ListViewsQuery(),
-		g.viewPostfix,
-	)
-	if err != nil {
-		return fmt.Errorf("Failed to select views: %+v", err)
+// getTable retrieves the Table configured for the table in the given schema.
+// A configuration for the schema qualified name takes precedence over the unqualified one.
+func (g *Generator) getTable(schema, tableName string) *Table {
+	if table, ok := g.tables[qualifiedName(schema, tableName)]; ok {
+		return table
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var viewName string
+	return g.GetTable(tableName)
+}
+
+// existingView is a view found in the database.
+type existingView struct {
+	name         objectName
+	materialized bool
+}
+
+func (g *Generator) loopExistingViews(
+	ctx context.Context,
+	q Querier,
+	viewFunc func(view existingView) error,
+) error {
+	views := make([]existingView, 0)
+
+	// Only QueryBuilders supporting materialized views return the kind of the views.
+	_, listsKinds := g.queryBuilder.(MaterializedViewQueryBuilder)
 
-		if err := rows.Scan(&viewName); err != nil {
-			return fmt.Errorf("Failed to scan viewname: %+v", err)
+	for _, schema := range g.viewSchemas() {
+		rows, err := q.QueryContext(
+			ctx,
+			g.queryBuilder.ListViewsQuery(),
+			schema,
+			g.listedViewPostfix(),
+		)
+		if err != nil {
+			return fmt.Errorf("Failed to select views: %+v", err)
 		}
 
-		if err := viewFunc(viewName); err != nil {
+		for rows.Next() {
+			var (
+				viewName     string
+				materialized bool
+			)
+
+			destinations := []interface{}{&viewName}
+			if listsKinds {
+				destinations = append(destinations, &materialized)
+			}
+
+			if err := rows.Scan(destinations...); err != nil {
+				rows.Close()
+				return fmt.Errorf("Failed to scan viewname: %+v", err)
+			}
+
+			views = append(
+				views,
+				existingView{name: objectName{schema: schema, name: viewName}, materialized: materialized},
+			)
+		}
+
+		// The rows have to be closed before viewFunc is called,
+		// as a transaction or connection can only run one statement at a time.
+		if err := closeRows(rows); err != nil {
+			return fmt.Errorf("Failed to select views: %+v", err)
+		}
+	}
+
+	for _, view := range views {
+		if err := viewFunc(view); err != nil {
 			return err
 		}
 	}
@@ -90,34 +231,131 @@ func (g *Generator) loopExistingViews(db *sql.DB, viewFunc func(viewName string)
 }
 
 // ClearViews removes any potentially existing views that exist with the configured postfix.
-func (g *Generator) ClearViews(db *sql.DB) error {
-	return g.loopExistingViews(
+// It is a shorthand for ClearViewsContext with a background context.
+func (g *Generator) ClearViews(db Querier) error {
+	return g.ClearViewsContext(context.Background(), db)
+}
+
+// ClearViewsContext removes any potentially existing views that exist with the configured postfix.
+func (g *Generator) ClearViewsContext(ctx context.Context, db Querier) error {
+	return inTransaction(
+		ctx,
 		db,
-		func(viewName string) error {
-			if _, err := db.Exec(g.queryBuilder.DropViewQuery(viewName)); err != nil {
-				return fmt.Errorf("Failed to drop view '%s': %+v", viewName, err)
+		func(q Querier) error {
+			plan := NewPlan()
+
+			if err := g.planClearViews(ctx, q, plan); err != nil {
+				return err
 			}
 
-			return nil
+			return plan.apply(ctx, q)
+		},
+	)
+}
+
+func (g *Generator) planClearViews(ctx context.Context, q Querier, plan *Plan) error {
+	return g.loopExistingViews(
+		ctx,
+		q,
+		func(view existingView) error {
+			return g.planDropView(plan, g.tableName(view.name).String(), view.name, view.materialized)
 		},
 	)
 }
 
-func (g *Generator) loopTables(db *sql.DB, tableFunc func(tableName string) error) error {
-	tableRows, err := db.Query(g.queryBuilder.ListTablesQuery())
+// planDropView adds the statement removing the view depending on whether it is materialized.
+func (g *Generator) planDropView(plan *Plan, tableName string, view objectName, materialized bool) error {
+	if !materialized {
+		plan.add(
+			StatementDropView,
+			tableName,
+			view.String(),
+			g.queryBuilder.DropViewQuery(g.quoteName(view)),
+		)
+
+		return nil
+	}
+
+	queryBuilder, ok := g.queryBuilder.(MaterializedViewQueryBuilder)
+	if !ok {
+		return unsupportedError("MaterializedViewQueryBuilder", "materialized views")
+	}
+
+	plan.add(
+		StatementDropMaterializedView,
+		tableName,
+		view.String(),
+		queryBuilder.DropMaterializedViewQuery(g.quoteName(view)),
+	)
+
+	return nil
+}
+
+// checkServerVersion checks the version of the database server if the QueryBuilder
+// implements the ServerVersionChecker interface.
+func (g *Generator) checkServerVersion(ctx context.Context, q Querier) error {
+	checker, ok := g.queryBuilder.(ServerVersionChecker)
+	if !ok || checker.ServerVersionQuery() == "" {
+		return nil
+	}
+
+	rows, err := q.QueryContext(ctx, checker.ServerVersionQuery())
 	if err != nil {
-		return fmt.Errorf("Failed to select tables: %+v", err)
+		return fmt.Errorf("Failed to select server version: %+v", err)
 	}
-	defer tableRows.Close()
 
-	for tableRows.Next() {
-		var tableName string
+	var version string
 
-		if err := tableRows.Scan(&tableName); err != nil {
-			return fmt.Errorf("Failed to scan table name: %+v", err)
+	if rows.Next() {
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return fmt.Errorf("Failed to scan server version: %+v", err)
 		}
+	}
 
-		if err := tableFunc(tableName); err != nil {
+	if err := closeRows(rows); err != nil {
+		return fmt.Errorf("Failed to select server version: %+v", err)
+	}
+
+	return checker.CheckServerVersion(version)
+}
+
+func (g *Generator) loopTables(
+	ctx context.Context,
+	q Querier,
+	tableFunc func(schema, tableName string) error,
+) error {
+	type schemaTable struct {
+		schema    string
+		tableName string
+	}
+
+	tables := make([]schemaTable, 0)
+
+	for _, sourceSchema := range g.listedSourceSchemas() {
+		tableRows, err := q.QueryContext(ctx, g.queryBuilder.ListTablesQuery(), sourceSchema)
+		if err != nil {
+			return fmt.Errorf("Failed to select tables: %+v", err)
+		}
+
+		for tableRows.Next() {
+			var table schemaTable
+
+			if err := tableRows.Scan(&table.schema, &table.tableName); err != nil {
+				tableRows.Close()
+				return fmt.Errorf("Failed to scan table name: %+v", err)
+			}
+
+			tables = append(tables, table)
+		}
+
+		if err := closeRows(tableRows); err != nil {
+			return fmt.Errorf("Failed to select tables: %+v", err)
+		}
+	}
+
+	for _, table := range tables {
+		if err := tableFunc(table.schema, table.tableName); err != nil {
 			return err
 		}
 	}
@@ -126,24 +364,69 @@ func (g *Generator) loopTables(db *sql.DB, tableFunc func(tableName string) erro
 }
 
 func (g *Generator) loopColumns(
-	db *sql.DB,
+	ctx context.Context,
+	q Querier,
+	schema string,
 	tableName string,
-	columnFunc func(columnName string) error,
+	columnFunc func(column Column) error,
 ) error {
-	columnRows, err := db.Query(g.queryBuilder.ListColumnsQuery(), tableName)
+	columnRows, err := q.QueryContext(ctx, g.queryBuilder.ListColumnsQuery(), schema, tableName)
 	if err != nil {
 		return fmt.Errorf("Failed to select columns: %+v", err)
 	}
-	defer columnRows.Close()
+
+	columns := make([]Column, 0)
 
 	for columnRows.Next() {
-		var columnName string
+		var (
+			column                 Column
+			columnDefault          sql.NullString
+			characterMaximumLength sql.NullInt64
+			udtName                sql.NullString
+			formattedType          sql.NullString
+			typeCategory           sql.NullString
+			domainName             sql.NullString
+		)
 
-		if err := columnRows.Scan(&columnName); err != nil {
+		if err := columnRows.Scan(
+			&column.Name,
+			&column.DataType,
+			&column.OrdinalPosition,
+			&column.IsNullable,
+			&columnDefault,
+			&characterMaximumLength,
+			&udtName,
+			&formattedType,
+			&typeCategory,
+			&domainName,
+		); err != nil {
+			columnRows.Close()
 			return err
 		}
 
-		if err := columnFunc(columnName); err != nil {
+		if columnDefault.Valid {
+			column.Default = &columnDefault.String
+		}
+
+		if characterMaximumLength.Valid {
+			length := int(characterMaximumLength.Int64)
+			column.CharacterMaximumLength = &length
+		}
+
+		column.UDTName = udtName.String
+		column.FormattedType = formattedType.String
+		column.TypeCategory = typeCategory.String
+		column.DomainName = domainName.String
+
+		columns = append(columns, column)
+	}
+
+	if err := closeRows(columnRows); err != nil {
+		return fmt.Errorf("Failed to select columns: %+v", err)
+	}
+
+	for _, column := range columns {
+		if err := columnFunc(column); err != nil {
 			return err
 		}
 	}
@@ -153,47 +436,571 @@ func (g *Generator) loopColumns(
 
 // CreateViews creates views named <table_name>_<postfix> for each table that could be found.
 // It uses the configuration set before CreateViews was called.
-func (g *Generator) CreateViews(db *sql.DB) error {
-	return g.loopTables(
+// It is a shorthand for CreateViewsContext with a background context.
+func (g *Generator) CreateViews(db Querier) error {
+	return g.CreateViewsContext(context.Background(), db)
+}
+
+// CreateViewsContext creates views named <table_name>_<postfix> for each table that could be found.
+// It uses the configuration set before CreateViewsContext was called.
+// Existing views are replaced, while existing materialized views and views switching
+// between the two kinds are dropped first.
+func (g *Generator) CreateViewsContext(ctx context.Context, db Querier) error {
+	return inTransaction(
+		ctx,
 		db,
-		func(tableName string) error {
-			columns := make([]string, 0)
+		func(q Querier) error {
+			plan := NewPlan()
 
-			table := g.GetTable(tableName)
+			if err := g.planCreateViews(ctx, q, plan); err != nil {
+				return err
+			}
+
+			return plan.apply(ctx, q)
+		},
+	)
+}
 
-			if err := g.loopColumns(
-				db,
-				tableName,
-				func(columnName string) error {
-					anonymizer := table.GetAnonymizer(columnName)
+// RegenerateViews removes all existing views with the configured postfix and creates
+// the views for each table that could be found.
+// Both steps are executed within a single transaction, so readers either see
+// the previous set of views or the new one, never a partial set.
+func (g *Generator) RegenerateViews(ctx context.Context, db Querier) error {
+	return inTransaction(
+		ctx,
+		db,
+		func(q Querier) error {
+			plan, err := g.Plan(ctx, q)
+			if err != nil {
+				return err
+			}
 
-					columns = append(
-						columns,
-						fmt.Sprintf("%s AS %s", anonymizer.Build(tableName, columnName), columnName),
-					)
+			return plan.apply(ctx, q)
+		},
+	)
+}
 
-					return nil
-				},
-			); err != nil {
+// Plan builds the statements RegenerateViews would execute without executing them.
+// The existing views, tables and columns are still read from the database.
+// The returned Plan can be reviewed, written as an SQL script with WriteSQL
+// and executed later with Apply.
+func (g *Generator) Plan(ctx context.Context, db Querier) (*Plan, error) {
+	if err := g.checkServerVersion(ctx, db); err != nil {
+		return nil, err
+	}
+
+	plan := NewPlan()
+
+	if err := g.planClearViews(ctx, db, plan); err != nil {
+		return nil, err
+	}
+
+	snapshot, err := g.Snapshot(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	// The existing views and grants are not relevant, as the views are dropped before being created.
+	if err := g.planSnapshotViews(snapshot, plan, make(map[objectName]bool), nil); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// PlanSnapshot builds the statements for creating the views from the given Snapshot
+// without requiring a database connection.
+// As the existing views are unknown, the Plan does not contain any statements for removing views
+// or for revoking stale grants. Materialized views are dropped before being created,
+// as they cannot be replaced.
+func (g *Generator) PlanSnapshot(snapshot *Snapshot) (*Plan, error) {
+	plan := NewPlan()
+
+	if err := g.planSnapshotViews(snapshot, plan, nil, nil); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+func (g *Generator) planCreateViews(ctx context.Context, q Querier, plan *Plan) error {
+	if err := g.checkServerVersion(ctx, q); err != nil {
+		return err
+	}
+
+	// The kind of the existing views is required to switch between views and materialized views.
+	existingViews := make(map[objectName]bool)
+
+	if err := g.loopExistingViews(
+		ctx,
+		q,
+		func(view existingView) error {
+			existingViews[view.name] = view.materialized
+
+			return nil
+		},
+	); err != nil {
+		return err
+	}
+
+	// Replacing a view keeps its grants, so stale grants have to be revoked.
+	grantees, err := g.existingGrantees(ctx, q)
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := g.Snapshot(ctx, q)
+	if err != nil {
+		return err
+	}
+
+	return g.planSnapshotViews(snapshot, plan, existingViews, grantees)
+}
+
+// planSnapshotViews adds the statements creating the views of the snapshot.
+// existingViews maps the existing views to whether they are materialized.
+// As materialized views cannot be replaced and a view cannot replace a materialized view or vice versa,
+// existing views are dropped first with the statement matching their kind unless both are plain views.
+// If existingViews is nil, the existing views are unknown and materialized views are always dropped first.
+func (g *Generator) planSnapshotViews(
+	snapshot *Snapshot,
+	plan *Plan,
+	existingViews map[objectName]bool,
+	grantees viewGrantees,
+) error {
+	definitions, err := g.viewDefinitions(snapshot)
+	if err != nil {
+		return err
+	}
+
+	for _, definition := range definitions {
+		existingGrantees := grantees[definition.view]
+
+		materialized, exists := existingViews[definition.view]
+		if existingViews == nil {
+			// Without knowing the existing views, materialized views are dropped in case they exist.
+			materialized, exists = definition.materialized, definition.materialized
+		}
+
+		if exists && (materialized || definition.materialized) {
+			if err := g.planDropView(plan, definition.table.String(), definition.view, materialized); err != nil {
 				return err
 			}
 
-			viewName := g.ViewName(tableName)
+			existingGrantees = nil
+		}
+
+		plan.addCreateView(definition)
+
+		if err := g.planGrants(plan, definition, existingGrantees); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// viewDefinition holds the query creating the view for a table
+// along with the columns omitted from the view.
+// For materialized views, indexQuery holds the query creating the unique index if configured.
+type viewDefinition struct {
+	table          objectName
+	view           objectName
+	query          string
+	omittedColumns []string
+	materialized   bool
+	indexQuery     string
+}
+
+func (g *Generator) viewDefinitions(snapshot *Snapshot) ([]viewDefinition, error) {
+	if g.strictConfig {
+		if err := g.checkConfig(snapshot); err != nil {
+			return nil, err
+		}
+	}
+
+	definitions := make([]viewDefinition, 0, len(snapshot.Tables))
+	viewTables := make(map[objectName]objectName)
+
+	for _, snapshotTable := range snapshot.Tables {
+		if !g.includeSnapshotTable(snapshotTable) {
+			continue
+		}
+
+		source := g.sourceTable(snapshotTable)
+		table := g.getTable(snapshotTable.Schema, snapshotTable.Name)
+
+		columns := newViewColumns(g.queryBuilder, source)
+
+		var omittedColumns []string
+
+		for _, column := range snapshotTable.Columns {
+			anonymizer := g.resolveAnonymizer(snapshotTable, column).anonymizer
+
+			if isOmitted(anonymizer) {
+				omittedColumns = append(omittedColumns, column.Name)
+				continue
+			}
+
+			partial, err := g.buildColumn(anonymizer, snapshotTable.Name, column)
+			if err != nil {
+				return nil, err
+			}
+
+			if role := g.columnUnmaskRole(table, column.Name); role != "" && !isPassthrough(anonymizer) {
+				// Materialized views store the values visible to the user refreshing them.
+				if g.materializedViews {
+					return nil, fmt.Errorf(
+						"Unmask role '%s' of column '%s' of table '%s' cannot be used with materialized views",
+						role,
+						column.Name,
+						source,
+					)
+				}
+
+				queryBuilder, ok := g.queryBuilder.(UnmaskQueryBuilder)
+				if !ok {
+					return nil, unsupportedError("UnmaskQueryBuilder", "unmask roles")
+				}
+
+				partial = queryBuilder.UnmaskQuery(
+					role,
+					QuoteColumnName(g.queryBuilder, snapshotTable.Name, column.Name),
+					partial,
+				)
+			}
+
+			if err := columns.add(table.outputName(column.Name), partial); err != nil {
+				return nil, err
+			}
+		}
+
+		// Computed columns are not cast to the type of their source column,
+		// as they usually differ in type.
+		for _, computed := range table.computedColumns {
+			column, ok := snapshotTable.column(computed.sourceColumn)
+			if !ok {
+				continue
+			}
 
-			if _, err := db.Exec(
-				g.queryBuilder.CreateViewQuery(viewName, tableName, columns),
+			if err := columns.add(
+				computed.name,
+				BuildDialect(g.queryBuilder, computed.anonymizer, snapshotTable.Name, column),
 			); err != nil {
-				return fmt.Errorf("Failed to create view '%s': %+v", viewName, err)
+				return nil, err
 			}
+		}
 
-			return nil
-		},
+		view := g.viewName(source)
+
+		if otherSource, ok := viewTables[view]; ok {
+			return nil, fmt.Errorf(
+				"Tables '%s' and '%s' would both create view '%s'",
+				otherSource,
+				source,
+				view,
+			)
+		}
+		viewTables[view] = source
+
+		conditions, err := g.viewConditions(snapshotTable, table)
+		if err != nil {
+			return nil, err
+		}
+
+		definition := viewDefinition{
+			table:          source,
+			view:           view,
+			omittedColumns: omittedColumns,
+			materialized:   g.materializedViews,
+		}
+
+		switch {
+		case g.materializedViews:
+			queryBuilder, ok := g.queryBuilder.(MaterializedViewQueryBuilder)
+			if !ok {
+				return nil, unsupportedError("MaterializedViewQueryBuilder", "materialized views")
+			}
+
+			definition.query = queryBuilder.CreateMaterializedViewQuery(
+				g.quoteName(view),
+				g.quoteName(source),
+				columns.columns,
+				conditions...,
+			)
+
+			if len(table.uniqueKey) > 0 {
+				definition.indexQuery = queryBuilder.CreateUniqueIndexQuery(
+					g.queryBuilder.QuoteIdentifier(uniqueIndexName(view)),
+					g.quoteName(view),
+					g.quoteIdentifiers(table.uniqueKey),
+				)
+			}
+		case len(conditions) > 0:
+			queryBuilder, ok := g.queryBuilder.(FilteredViewQueryBuilder)
+			if !ok {
+				return nil, unsupportedError("FilteredViewQueryBuilder", "row filters")
+			}
+
+			definition.query = queryBuilder.CreateFilteredViewQuery(
+				g.quoteName(view),
+				g.quoteName(source),
+				columns.columns,
+				conditions...,
+			)
+		default:
+			definition.query = g.queryBuilder.CreateViewQuery(
+				g.quoteName(view),
+				g.quoteName(source),
+				columns.columns,
+			)
+		}
+
+		definitions = append(definitions, definition)
+	}
+
+	return definitions, nil
+}
+
+// viewColumns collects the columns of a view as <partial query> AS <output name>
+// and ensures that every output name is unique.
+type viewColumns struct {
+	queryBuilder QueryBuilder
+	table        objectName
+	columns      []string
+	names        map[string]bool
+}
+
+func newViewColumns(queryBuilder QueryBuilder, table objectName) *viewColumns {
+	return &viewColumns{
+		queryBuilder: queryBuilder,
+		table:        table,
+		columns:      make([]string, 0),
+		names:        make(map[string]bool),
+	}
+}
+
+func (c *viewColumns) add(name string, partial string) error {
+	if c.names[name] {
+		return fmt.Errorf("Column '%s' is defined more than once in the view of table '%s'", name, c.table)
+	}
+	c.names[name] = true
+
+	c.columns = append(
+		c.columns,
+		fmt.Sprintf("%s AS %s", partial, c.queryBuilder.QuoteIdentifier(name)),
 	)
+
+	return nil
+}
+
+// viewConditions returns the conditions restricting the rows of the view of the table.
+// Views of structure only tables do not contain any rows.
+func (g *Generator) viewConditions(snapshotTable SnapshotTable, table *Table) ([]string, error) {
+	if table.structureOnly ||
+		matchAnyTable(g.structureOnly, objectName{schema: snapshotTable.Schema, name: snapshotTable.Name}) {
+		queryBuilder, ok := g.queryBuilder.(FilteredViewQueryBuilder)
+		if !ok {
+			return nil, unsupportedError("FilteredViewQueryBuilder", "structure only tables")
+		}
+
+		return []string{queryBuilder.NoRowsCondition()}, nil
+	}
+
+	return buildRowFilters(table.conditions(), snapshotTable.Name)
+}
+
+// buildColumn builds the partial query of the anonymizer for the column.
+// With type preserving casts, the result is cast to the type of the column
+// unless the column is passed through as it is or the anonymizer opted out.
+func (g *Generator) buildColumn(anonymizer Anonymizer, tableName string, column Column) (string, error) {
+	partial := BuildDialect(g.queryBuilder, anonymizer, tableName, column)
+
+	typeName := column.TypeName(g.queryBuilder)
+	if !g.typeCasts || typeName == "" {
+		return partial, nil
+	}
+
+	if _, ok := anonymizer.(*uncastAnonymizer); ok || isPassthrough(anonymizer) {
+		return partial, nil
+	}
+
+	queryBuilder, ok := g.queryBuilder.(CastQueryBuilder)
+	if !ok {
+		return "", unsupportedError("CastQueryBuilder", "type preserving casts")
+	}
+
+	return queryBuilder.CastQuery(partial, typeName), nil
+}
+
+// isPassthrough reports whether the anonymizer exposes the column as it is.
+func isPassthrough(anonymizer Anonymizer) bool {
+	switch unwrapAnonymizer(anonymizer).(type) {
+	case *NoopAnonymizer, *PassthroughAnonymizer:
+		return true
+	}
+
+	return false
+}
+
+// columnUnmaskRole returns the role allowed to see the original value of the column.
+// A role configured for the column takes precedence over the role configured for the table,
+// which takes precedence over the role configured for the Generator.
+func (g *Generator) columnUnmaskRole(table *Table, columnName string) string {
+	if role, ok := table.columnUnmaskRoles[columnName]; ok {
+		return role
+	}
+
+	if table.unmaskRole != "" {
+		return table.unmaskRole
+	}
+
+	return g.unmaskRole
 }
 
 // ViewName builds the view name from the table name and the postfix to <table_name>_<postfix>.
+// If the table name is qualified with a schema, the view is created in the same schema.
+// If a target schema is configured, the view is named <target_schema>.<table_name> instead.
 func (g *Generator) ViewName(tableName string) string {
-	return fmt.Sprintf("%s_%s", tableName, g.viewPostfix)
+	return g.viewName(splitQualifiedName(tableName)).String()
+}
+
+func (g *Generator) viewName(table objectName) objectName {
+	if g.targetSchema != "" {
+		return objectName{schema: g.targetSchema, name: table.name}
+	}
+
+	return objectName{
+		schema: table.schema,
+		name:   fmt.Sprintf("%s_%s", table.name, g.viewPostfix),
+	}
+}
+
+// tableName is the reverse of viewName and derives the table name from the view name.
+// For views within a target schema, the schema of the table is unknown.
+func (g *Generator) tableName(view objectName) objectName {
+	if g.targetSchema != "" {
+		return objectName{name: view.name}
+	}
+
+	return objectName{
+		schema: view.schema,
+		name:   strings.TrimSuffix(view.name, fmt.Sprintf("_%s", g.viewPostfix)),
+	}
+}
+
+// sourceTable returns the name used to select from the table in the view.
+// The name is only qualified with the schema if source or target schemas are configured
+// so that views in the current schema keep referencing the tables in the same way.
+func (g *Generator) sourceTable(table SnapshotTable) objectName {
+	if len(g.sourceSchemas) == 0 && g.targetSchema == "" {
+		return objectName{name: table.Name}
+	}
+
+	return objectName{schema: table.Schema, name: table.Name}
+}
+
+// includeTable reports whether the table passes the include and exclude filters.
+// If include filters are configured, the table has to match at least one of them.
+// Tables matching any exclude filter are excluded.
+func (g *Generator) includeTable(table objectName) bool {
+	if len(g.includedTables) > 0 && !matchAnyTable(g.includedTables, table) {
+		return false
+	}
+
+	return !matchAnyTable(g.excludedTables, table)
+}
+
+// includeSnapshotTable reports whether the table of the snapshot passes the filters.
+func (g *Generator) includeSnapshotTable(table SnapshotTable) bool {
+	return g.includeTable(objectName{schema: table.Schema, name: table.Name})
+}
+
+// matchAnyTable reports whether any of the matchers matches the name
+// or the schema qualified name of the table.
+func matchAnyTable(matchers []NameMatcher, table objectName) bool {
+	for _, matcher := range matchers {
+		if matcher.Match(table.name) || matcher.Match(table.String()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// listedSourceSchemas returns the schemas to list the tables from.
+// An empty schema name refers to the current schema.
+func (g *Generator) listedSourceSchemas() []string {
+	if len(g.sourceSchemas) == 0 {
+		return []string{""}
+	}
+
+	return g.sourceSchemas
+}
+
+// viewSchemas returns the schemas in which the views are created.
+// An empty schema name refers to the current schema.
+func (g *Generator) viewSchemas() []string {
+	if g.targetSchema != "" {
+		return []string{g.targetSchema}
+	}
+
+	return g.listedSourceSchemas()
+}
+
+// listedViewPostfix returns the postfix existing views are listed by.
+// Every view within a dedicated target schema is considered to be managed by the Generator.
+func (g *Generator) listedViewPostfix() string {
+	if g.targetSchema != "" {
+		return ""
+	}
+
+	return g.viewPostfix
+}
+
+// quoteName quotes the schema and name of the object using the QueryBuilder.
+func (g *Generator) quoteName(object objectName) string {
+	if object.schema == "" {
+		return g.queryBuilder.QuoteIdentifier(object.name)
+	}
+
+	return fmt.Sprintf(
+		"%s.%s",
+		g.queryBuilder.QuoteIdentifier(object.schema),
+		g.queryBuilder.QuoteIdentifier(object.name),
+	)
+}
+
+// objectName is the name of a table or view along with its schema.
+// An empty schema refers to the current schema.
+type objectName struct {
+	schema string
+	name   string
+}
+
+// String joins the schema and name to <schema>.<name> without quoting them.
+// If the schema is empty, the name is returned as is.
+func (o objectName) String() string {
+	return qualifiedName(o.schema, o.name)
+}
+
+// qualifiedName joins the schema and name to <schema>.<name>.
+// If the schema is empty, the name is returned as is.
+func qualifiedName(schema, name string) string {
+	if schema == "" {
+		return name
+	}
+
+	return fmt.Sprintf("%s.%s", schema, name)
+}
+
+// splitQualifiedName is the reverse of qualifiedName.
+func splitQualifiedName(name string) objectName {
+	if i := strings.Index(name, "."); i >= 0 {
+		return objectName{schema: name[:i], name: name[i+1:]}
+	}
+
+	return objectName{name: name}
 }
 
 // GeneratorOption is a function type following the option function pattern.
@@ -206,3 +1013,114 @@ func WithViewPostfix(viewPostfix string) GeneratorOption {
 		g.viewPostfix = viewPostfix
 	}
 }
+
+// WithSourceSchemas is a GeneratorOption builder, which allows configuring the schemas
+// in which tables are looked up. By default, only the current schema is used.
+// Views are created in the schema of their table unless a target schema is configured.
+func WithSourceSchemas(schemas ...string) GeneratorOption {
+	return func(g *Generator) {
+		g.sourceSchemas = schemas
+	}
+}
+
+// WithTargetSchema is a GeneratorOption builder, which allows configuring a dedicated schema
+// for the views. The views are named <target_schema>.<table_name> without a postfix.
+// As all views within the target schema are considered to be managed by the Generator,
+// ClearViews removes every view within it.
+func WithTargetSchema(schema string) GeneratorOption {
+	return func(g *Generator) {
+		g.targetSchema = schema
+	}
+}
+
+// WithStrictConfig is a GeneratorOption, which makes the Generator fail
+// with a *MissingConfigError if a configured table or column does not exist.
+// By default, such tables and columns are ignored.
+func WithStrictConfig() GeneratorOption {
+	return func(g *Generator) {
+		g.strictConfig = true
+	}
+}
+
+// WithDefaultAnonymizer is a GeneratorOption builder, which allows configuring the Anonymizer
+// used for every column without an explicitly configured Anonymizer instead of the NoopAnonymizer.
+// Combined with Table.Allow, only columns that were opted in are exposed as they are.
+func WithDefaultAnonymizer(anonymizer Anonymizer) GeneratorOption {
+	return func(g *Generator) {
+		g.defaultAnonymizer = anonymizer
+	}
+}
+
+// WithTypePreservingCasts is a GeneratorOption, which makes the Generator cast the result
+// of every anonymizer to the exact type of the column, unless wrapped with WithoutTypeCast.
+func WithTypePreservingCasts() GeneratorOption {
+	return func(g *Generator) {
+		g.typeCasts = true
+	}
+}
+
+// WithIncludedTables is a GeneratorOption builder, which restricts the views to tables
+// matched by at least one of the given NameMatchers.
+// Tables are matched by their name as well as by their schema qualified name <schema>.<table>.
+// Existing views of tables not included are dropped like the views of tables that no longer exist.
+func WithIncludedTables(matchers ...NameMatcher) GeneratorOption {
+	return func(g *Generator) {
+		g.includedTables = append(g.includedTables, matchers...)
+	}
+}
+
+// WithExcludedTables is a GeneratorOption builder, which excludes tables matched
+// by any of the given NameMatchers from getting a view.
+// Tables are matched by their name as well as by their schema qualified name <schema>.<table>.
+// Existing views of excluded tables are dropped like the views of tables that no longer exist.
+func WithExcludedTables(matchers ...NameMatcher) GeneratorOption {
+	return func(g *Generator) {
+		g.excludedTables = append(g.excludedTables, matchers...)
+	}
+}
+
+// WithStructureOnlyTables is a GeneratorOption builder, which makes the views of tables
+// matched by any of the given NameMatchers expose the columns of the tables without any rows.
+// Tables are matched by their name as well as by their schema qualified name <schema>.<table>.
+// It is the equivalent of Table.StructureOnly for multiple tables.
+func WithStructureOnlyTables(matchers ...NameMatcher) GeneratorOption {
+	return func(g *Generator) {
+		g.structureOnly = append(g.structureOnly, matchers...)
+	}
+}
+
+// WithUnmaskRole is a GeneratorOption builder, which allows members of the given role
+// to see the original values through the views, while everyone else sees the anonymized values.
+func WithUnmaskRole(role string) GeneratorOption {
+	return func(g *Generator) {
+		g.unmaskRole = role
+	}
+}
+
+// WithGrantees is a GeneratorOption builder, which makes the Generator grant SELECT
+// on every view to the given roles. SELECT is revoked from all other roles apart from the owner
+// of the view, so that the permissions are managed in one place.
+// Grants are only managed if at least one grantee is configured.
+func WithGrantees(roles ...string) GeneratorOption {
+	return func(g *Generator) {
+		g.grantees = append(g.grantees, roles...)
+	}
+}
+
+// WithRevokeBaseTables is a GeneratorOption, which makes the Generator revoke SELECT
+// on the tables from the roles configured with WithGrantees, so that they can only read
+// the anonymized data through the views.
+// Privileges the roles obtain through PUBLIC or membership in other roles are not affected.
+func WithRevokeBaseTables() GeneratorOption {
+	return func(g *Generator) {
+		g.revokeBaseTables = true
+	}
+}
+
+// WithMaterializedViews is a GeneratorOption, which makes the Generator create materialized views
+// instead of views, which have to be refreshed with RefreshViews.
+func WithMaterializedViews() GeneratorOption {
+	return func(g *Generator) {
+		g.materializedViews = true
+	}
+}
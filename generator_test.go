@@ -1,8 +1,10 @@
 package gotidus
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -10,6 +12,22 @@ import (
 	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
 )
 
+// newColumnRows initializes the rows returned by the ListColumnsQuery.
+func newColumnRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{
+		"column_name",
+		"data_type",
+		"ordinal_position",
+		"is_nullable",
+		"column_default",
+		"character_maximum_length",
+		"udt_name",
+		"formatted_type",
+		"type_category",
+		"domain_name",
+	})
+}
+
 func TestNewGenerator(t *testing.T) {
 	queryBuilder := &mockQueryBuilder{}
 
@@ -54,6 +72,53 @@ func TestGeneratorAddAndGetTable(t *testing.T) {
 	testutils.CompareStructs(defaultTable, expectedTable, t)
 }
 
+func TestGeneratorGetTableWithSchema(t *testing.T) {
+	generator := NewGenerator(&mockQueryBuilder{})
+
+	fooTable := NewTable().AddAnonymizer("bar", NewNoopAnonymizer())
+	billingFooTable := NewTable().AddAnonymizer("baz", NewNoopAnonymizer())
+
+	generator.AddTable("foo", fooTable)
+	generator.AddTable("billing.foo", billingFooTable)
+
+	testutils.CompareStructs(generator.getTable("billing", "foo"), billingFooTable, t)
+	testutils.CompareStructs(generator.getTable("public", "foo"), fooTable, t)
+	testutils.CompareStructs(generator.getTable("public", "baz"), NewTable(), t)
+}
+
+func TestGeneratorClearViewsWithTargetSchema(t *testing.T) {
+	queryBuilder := &mockQueryBuilder{}
+
+	db, dbMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to initialize DB mock")
+	}
+
+	rows := sqlmock.NewRows([]string{"viewname", "materialized"})
+	rows.AddRow("users", false)
+
+	dbMock.ExpectBegin()
+
+	dbMock.
+		ExpectQuery(queryBuilder.ListViewsQuery()).
+		WithArgs("anonymized", "").
+		WillReturnRows(rows)
+
+	dbMock.
+		ExpectExec(queryBuilder.DropViewQuery("anonymized.users")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	dbMock.ExpectCommit()
+
+	generator := NewGenerator(queryBuilder, WithTargetSchema("anonymized"))
+
+	testutils.CompareStructs(generator.ClearViews(db), nil, t)
+
+	if err := dbMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Did not execute expected queries")
+	}
+}
+
 func TestGeneratorClearViews(t *testing.T) {
 	queryBuilder := &mockQueryBuilder{}
 
@@ -65,27 +130,33 @@ func TestGeneratorClearViews(t *testing.T) {
 		{
 			title: "view selection fails",
 			setupMock: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"viewname"})
-				rows.AddRow("foo_anonymized")
-				rows.AddRow("foo2_anonymized")
+				rows := sqlmock.NewRows([]string{"viewname", "materialized"})
+				rows.AddRow("foo_anonymized", false)
+				rows.AddRow("foo2_anonymized", false)
+
+				mock.ExpectBegin()
 
 				mock.
 					ExpectQuery(queryBuilder.ListViewsQuery()).
-					WithArgs("anonymized").
+					WithArgs("", "anonymized").
 					WillReturnError(errors.New("simulated failure"))
+
+				mock.ExpectRollback()
 			},
 			expectedError: errors.New("Failed to select views: simulated failure"),
 		},
 		{
 			title: "second view removal fails",
 			setupMock: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"viewname"})
-				rows.AddRow("foo_anonymized")
-				rows.AddRow("foo2_anonymized")
+				rows := sqlmock.NewRows([]string{"viewname", "materialized"})
+				rows.AddRow("foo_anonymized", false)
+				rows.AddRow("foo2_anonymized", false)
+
+				mock.ExpectBegin()
 
 				mock.
 					ExpectQuery(queryBuilder.ListViewsQuery()).
-					WithArgs("anonymized").
+					WithArgs("", "anonymized").
 					WillReturnRows(rows)
 
 				mock.
@@ -95,9 +166,43 @@ func TestGeneratorClearViews(t *testing.T) {
 				mock.
 					ExpectExec(queryBuilder.DropViewQuery("foo2_anonymized")).
 					WillReturnError(errors.New("simulated failure"))
+
+				mock.ExpectRollback()
 			},
 			expectedError: errors.New("Failed to drop view 'foo2_anonymized': simulated failure"),
 		},
+		{
+			title: "view removal succeeds",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"viewname", "materialized"})
+				rows.AddRow("foo_anonymized", false)
+				rows.AddRow("foo2_anonymized", false)
+
+				mock.ExpectBegin()
+
+				mock.
+					ExpectQuery(queryBuilder.ListViewsQuery()).
+					WithArgs("", "anonymized").
+					WillReturnRows(rows)
+
+				mock.
+					ExpectExec(queryBuilder.DropViewQuery("foo_anonymized")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.
+					ExpectExec(queryBuilder.DropViewQuery("foo2_anonymized")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectCommit()
+			},
+		},
+		{
+			title: "transaction cannot be started",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin().WillReturnError(errors.New("simulated failure"))
+			},
+			expectedError: errors.New("Failed to begin transaction: simulated failure"),
+		},
 	}
 
 	for _, c := range cases {
@@ -147,13 +252,23 @@ func TestGeneratorCreateViews(t *testing.T) {
 			title:          "table selection fails",
 			buildGenerator: defaultGeneratorFunc,
 			setupMock: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"tablename"})
-				rows.AddRow("foo")
-				rows.AddRow("foo2")
+				rows := sqlmock.NewRows([]string{"schemaname", "tablename"})
+				rows.AddRow("public", "foo")
+				rows.AddRow("public", "foo2")
+
+				mock.ExpectBegin()
+
+				mock.
+					ExpectQuery(queryBuilder.ListViewsQuery()).
+					WithArgs("", "anonymized").
+					WillReturnRows(sqlmock.NewRows([]string{"viewname", "materialized"}))
 
 				mock.
 					ExpectQuery(queryBuilder.ListTablesQuery()).
+					WithArgs("").
 					WillReturnError(errors.New("simulated failure"))
+
+				mock.ExpectRollback()
 			},
 			expectedError: errors.New("Failed to select tables: simulated failure"),
 		},
@@ -161,18 +276,28 @@ func TestGeneratorCreateViews(t *testing.T) {
 			title:          "column selection fails",
 			buildGenerator: defaultGeneratorFunc,
 			setupMock: func(mock sqlmock.Sqlmock) {
-				tableRows := sqlmock.NewRows([]string{"tablename"})
-				tableRows.AddRow("foo")
-				tableRows.AddRow("foo2")
+				tableRows := sqlmock.NewRows([]string{"schemaname", "tablename"})
+				tableRows.AddRow("public", "foo")
+				tableRows.AddRow("public", "foo2")
+
+				mock.ExpectBegin()
+
+				mock.
+					ExpectQuery(queryBuilder.ListViewsQuery()).
+					WithArgs("", "anonymized").
+					WillReturnRows(sqlmock.NewRows([]string{"viewname", "materialized"}))
 
 				mock.
 					ExpectQuery(queryBuilder.ListTablesQuery()).
+					WithArgs("").
 					WillReturnRows(tableRows)
 
 				mock.
 					ExpectQuery(queryBuilder.ListColumnsQuery()).
-					WithArgs("foo").
+					WithArgs("public", "foo").
 					WillReturnError(errors.New("simulated failure"))
+
+				mock.ExpectRollback()
 			},
 			expectedError: errors.New("Failed to select columns: simulated failure"),
 		},
@@ -180,23 +305,40 @@ func TestGeneratorCreateViews(t *testing.T) {
 			title:          "view creation fails",
 			buildGenerator: defaultGeneratorFunc,
 			setupMock: func(mock sqlmock.Sqlmock) {
-				tableRows := sqlmock.NewRows([]string{"tablename"})
-				tableRows.AddRow("foo")
-				tableRows.AddRow("foo2")
+				tableRows := sqlmock.NewRows([]string{"schemaname", "tablename"})
+				tableRows.AddRow("public", "foo")
+				tableRows.AddRow("public", "foo2")
+
+				mock.ExpectBegin()
+
+				mock.
+					ExpectQuery(queryBuilder.ListViewsQuery()).
+					WithArgs("", "anonymized").
+					WillReturnRows(sqlmock.NewRows([]string{"viewname", "materialized"}))
 
 				mock.
 					ExpectQuery(queryBuilder.ListTablesQuery()).
+					WithArgs("").
 					WillReturnRows(tableRows)
 
-				fooColumnRows := sqlmock.NewRows([]string{"columnname"})
-				fooColumnRows.AddRow("id")
-				fooColumnRows.AddRow("bar")
+				fooColumnRows := newColumnRows()
+				fooColumnRows.AddRow("id", "integer", 1, false, nil, nil, nil, nil, nil, nil)
+				fooColumnRows.AddRow("bar", "text", 2, false, nil, nil, nil, nil, nil, nil)
 
 				mock.
 					ExpectQuery(queryBuilder.ListColumnsQuery()).
-					WithArgs("foo").
+					WithArgs("public", "foo").
 					WillReturnRows(fooColumnRows)
 
+				foo2ColumnRows := newColumnRows()
+				foo2ColumnRows.AddRow("id", "integer", 1, false, nil, nil, nil, nil, nil, nil)
+				foo2ColumnRows.AddRow("amount", "numeric", 2, false, nil, nil, nil, nil, nil, nil)
+
+				mock.
+					ExpectQuery(queryBuilder.ListColumnsQuery()).
+					WithArgs("public", "foo2").
+					WillReturnRows(foo2ColumnRows)
+
 				mock.
 					ExpectExec(
 						queryBuilder.CreateViewQuery(
@@ -209,6 +351,8 @@ func TestGeneratorCreateViews(t *testing.T) {
 						),
 					).
 					WillReturnError(errors.New("simulated failure"))
+
+				mock.ExpectRollback()
 			},
 			expectedError: errors.New("Failed to create view 'foo_anonymized': simulated failure"),
 		},
@@ -216,23 +360,40 @@ func TestGeneratorCreateViews(t *testing.T) {
 			title:          "view creation succeeds",
 			buildGenerator: defaultGeneratorFunc,
 			setupMock: func(mock sqlmock.Sqlmock) {
-				tableRows := sqlmock.NewRows([]string{"tablename"})
-				tableRows.AddRow("foo")
-				tableRows.AddRow("foo2")
+				tableRows := sqlmock.NewRows([]string{"schemaname", "tablename"})
+				tableRows.AddRow("public", "foo")
+				tableRows.AddRow("public", "foo2")
+
+				mock.ExpectBegin()
+
+				mock.
+					ExpectQuery(queryBuilder.ListViewsQuery()).
+					WithArgs("", "anonymized").
+					WillReturnRows(sqlmock.NewRows([]string{"viewname", "materialized"}))
 
 				mock.
 					ExpectQuery(queryBuilder.ListTablesQuery()).
+					WithArgs("").
 					WillReturnRows(tableRows)
 
-				fooColumnRows := sqlmock.NewRows([]string{"columnname"})
-				fooColumnRows.AddRow("id")
-				fooColumnRows.AddRow("bar")
+				fooColumnRows := newColumnRows()
+				fooColumnRows.AddRow("id", "integer", 1, false, nil, nil, nil, nil, nil, nil)
+				fooColumnRows.AddRow("bar", "text", 2, false, nil, nil, nil, nil, nil, nil)
 
 				mock.
 					ExpectQuery(queryBuilder.ListColumnsQuery()).
-					WithArgs("foo").
+					WithArgs("public", "foo").
 					WillReturnRows(fooColumnRows)
 
+				foo2ColumnRows := newColumnRows()
+				foo2ColumnRows.AddRow("id", "integer", 1, false, nil, nil, nil, nil, nil, nil)
+				foo2ColumnRows.AddRow("amount", "numeric", 2, false, nil, nil, nil, nil, nil, nil)
+
+				mock.
+					ExpectQuery(queryBuilder.ListColumnsQuery()).
+					WithArgs("public", "foo2").
+					WillReturnRows(foo2ColumnRows)
+
 				mock.
 					ExpectExec(
 						queryBuilder.CreateViewQuery(
@@ -246,15 +407,6 @@ func TestGeneratorCreateViews(t *testing.T) {
 					).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
-				foo2ColumnRows := sqlmock.NewRows([]string{"columnname"})
-				foo2ColumnRows.AddRow("id")
-				foo2ColumnRows.AddRow("amount")
-
-				mock.
-					ExpectQuery(queryBuilder.ListColumnsQuery()).
-					WithArgs("foo2").
-					WillReturnRows(foo2ColumnRows)
-
 				mock.
 					ExpectExec(
 						queryBuilder.CreateViewQuery(
@@ -267,6 +419,8 @@ func TestGeneratorCreateViews(t *testing.T) {
 						),
 					).
 					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectCommit()
 			},
 		},
 	}
@@ -295,6 +449,219 @@ func TestGeneratorCreateViews(t *testing.T) {
 	}
 }
 
+func TestGeneratorRegenerateViews(t *testing.T) {
+	queryBuilder := &mockQueryBuilder{}
+
+	cases := []struct {
+		title         string
+		buildContext  func() context.Context
+		setupMock     func(sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			title:        "view creation fails after views were dropped",
+			buildContext: context.Background,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				viewRows := sqlmock.NewRows([]string{"viewname", "materialized"})
+				viewRows.AddRow("foo_anonymized", false)
+
+				mock.ExpectBegin()
+
+				mock.
+					ExpectQuery(queryBuilder.ListViewsQuery()).
+					WithArgs("", "anonymized").
+					WillReturnRows(viewRows)
+
+				tableRows := sqlmock.NewRows([]string{"schemaname", "tablename"})
+				tableRows.AddRow("public", "foo")
+
+				mock.
+					ExpectQuery(queryBuilder.ListTablesQuery()).
+					WithArgs("").
+					WillReturnRows(tableRows)
+
+				columnRows := newColumnRows()
+				columnRows.AddRow("id", "integer", 1, false, nil, nil, nil, nil, nil, nil)
+
+				mock.
+					ExpectQuery(queryBuilder.ListColumnsQuery()).
+					WithArgs("public", "foo").
+					WillReturnRows(columnRows)
+
+				mock.
+					ExpectExec(queryBuilder.DropViewQuery("foo_anonymized")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.
+					ExpectExec(
+						queryBuilder.CreateViewQuery("foo_anonymized", "foo", []string{"foo.id AS id"}),
+					).
+					WillReturnError(errors.New("simulated failure"))
+
+				mock.ExpectRollback()
+			},
+			expectedError: errors.New("Failed to create view 'foo_anonymized': simulated failure"),
+		},
+		{
+			title:        "views are regenerated",
+			buildContext: context.Background,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				viewRows := sqlmock.NewRows([]string{"viewname", "materialized"})
+				viewRows.AddRow("foo_anonymized", false)
+
+				mock.ExpectBegin()
+
+				mock.
+					ExpectQuery(queryBuilder.ListViewsQuery()).
+					WithArgs("", "anonymized").
+					WillReturnRows(viewRows)
+
+				tableRows := sqlmock.NewRows([]string{"schemaname", "tablename"})
+				tableRows.AddRow("public", "foo")
+
+				mock.
+					ExpectQuery(queryBuilder.ListTablesQuery()).
+					WithArgs("").
+					WillReturnRows(tableRows)
+
+				columnRows := newColumnRows()
+				columnRows.AddRow("id", "integer", 1, false, nil, nil, nil, nil, nil, nil)
+
+				mock.
+					ExpectQuery(queryBuilder.ListColumnsQuery()).
+					WithArgs("public", "foo").
+					WillReturnRows(columnRows)
+
+				mock.
+					ExpectExec(queryBuilder.DropViewQuery("foo_anonymized")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.
+					ExpectExec(
+						queryBuilder.CreateViewQuery("foo_anonymized", "foo", []string{"foo.id AS id"}),
+					).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectCommit()
+			},
+		},
+		{
+			title: "context is cancelled",
+			buildContext: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				return ctx
+			},
+			setupMock:     func(mock sqlmock.Sqlmock) {},
+			expectedError: fmt.Errorf("Failed to begin transaction: %+v", context.Canceled),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to initialize DB mock")
+			}
+
+			c.setupMock(dbMock)
+
+			generator := NewGenerator(queryBuilder)
+
+			testutils.CompareStructs(
+				generator.RegenerateViews(c.buildContext(), db),
+				c.expectedError,
+				t,
+			)
+
+			if err := dbMock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Did not execute expected queries")
+			}
+		})
+	}
+}
+
+func TestGeneratorCheckServerVersion(t *testing.T) {
+	cases := []struct {
+		title         string
+		setupMock     func(sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			title: "version selection fails",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+
+				mock.
+					ExpectQuery("server_version_query").
+					WillReturnError(errors.New("simulated failure"))
+
+				mock.ExpectRollback()
+			},
+			expectedError: errors.New("Failed to select server version: simulated failure"),
+		},
+		{
+			title: "version not supported",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+
+				mock.
+					ExpectQuery("server_version_query").
+					WillReturnRows(sqlmock.NewRows([]string{"server_version_num"}).AddRow("140009"))
+
+				mock.ExpectRollback()
+			},
+			expectedError: errors.New("Unsupported server version 140009"),
+		},
+		{
+			title: "version supported",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+
+				mock.
+					ExpectQuery("server_version_query").
+					WillReturnRows(sqlmock.NewRows([]string{"server_version_num"}).AddRow("150004"))
+
+				mock.
+					ExpectQuery("list_view_query").
+					WithArgs("", "anonymized").
+					WillReturnRows(sqlmock.NewRows([]string{"viewname", "materialized"}))
+
+				mock.
+					ExpectQuery("list_tables_query").
+					WithArgs("").
+					WillReturnRows(sqlmock.NewRows([]string{"schemaname", "tablename"}))
+
+				mock.ExpectCommit()
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to initialize DB mock")
+			}
+
+			c.setupMock(dbMock)
+
+			generator := NewGenerator(&versionCheckingQueryBuilder{minimumVersion: "150000"})
+
+			testutils.CompareStructs(
+				generator.CreateViewsContext(context.Background(), db),
+				c.expectedError,
+				t,
+			)
+
+			if err := dbMock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Did not execute expected queries: %+v", err)
+			}
+		})
+	}
+}
+
 func TestGeneratorViewName(t *testing.T) {
 	cases := []struct {
 		title     string
@@ -318,6 +685,21 @@ func TestGeneratorViewName(t *testing.T) {
 
 			expectedViewName: "bar_bazbaz",
 		},
+		{
+			title:     "with schema qualified table",
+			tableName: "billing.bar",
+
+			expectedViewName: "billing.bar_anonymized",
+		},
+		{
+			title:     "with target schema",
+			tableName: "billing.bar",
+			options: []GeneratorOption{
+				WithTargetSchema("anonymized"),
+			},
+
+			expectedViewName: "anonymized.bar",
+		},
 	}
 
 	for _, c := range cases {
@@ -331,6 +713,12 @@ func TestGeneratorViewName(t *testing.T) {
 	}
 }
 
+// plainIdentifierPattern matches the identifiers the mockQueryBuilder leaves unquoted
+// unless they are one of the mockKeywords.
+var plainIdentifierPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+var mockKeywords = map[string]bool{"column": true, "order": true, "table": true, "user": true}
+
 type mockQueryBuilder struct{}
 
 func (mqb *mockQueryBuilder) ListViewsQuery() string {
@@ -340,6 +728,34 @@ func (mqb *mockQueryBuilder) DropViewQuery(viewName string) string {
 	return fmt.Sprintf("drop_view_query:%s", viewName)
 }
 
+func (mqb *mockQueryBuilder) DropMaterializedViewQuery(viewName string) string {
+	return fmt.Sprintf("drop_materialized_view_query:%s", viewName)
+}
+
+func (mqb *mockQueryBuilder) ListViewFingerprintsQuery() string {
+	return "list_view_fingerprints_query"
+}
+
+func (mqb *mockQueryBuilder) CommentViewQuery(viewName string, comment string) string {
+	return fmt.Sprintf("comment_view_query:%s;%s", viewName, comment)
+}
+
+func (mqb *mockQueryBuilder) CommentMaterializedViewQuery(viewName string, comment string) string {
+	return fmt.Sprintf("comment_materialized_view_query:%s;%s", viewName, comment)
+}
+
+func (mqb *mockQueryBuilder) ListViewGranteesQuery() string {
+	return "list_view_grantees_query"
+}
+
+func (mqb *mockQueryBuilder) GrantSelectQuery(objectName string, roles []string) string {
+	return fmt.Sprintf("grant_select_query:%s;%s", objectName, strings.Join(roles, "|"))
+}
+
+func (mqb *mockQueryBuilder) RevokeSelectQuery(objectName string, roles []string) string {
+	return fmt.Sprintf("revoke_select_query:%s;%s", objectName, strings.Join(roles, "|"))
+}
+
 func (mqb *mockQueryBuilder) ListTablesQuery() string {
 	return "list_tables_query"
 }
@@ -348,12 +764,262 @@ func (mqb *mockQueryBuilder) ListColumnsQuery() string {
 	return "list_columns_query"
 }
 
-func (mqb *mockQueryBuilder) CreateViewQuery(
+func (mqb *mockQueryBuilder) CastQuery(expression string, typeName string) string {
+	return fmt.Sprintf("cast_query:%s;%s", expression, typeName)
+}
+
+func (mqb *mockQueryBuilder) NoRowsCondition() string {
+	return "no_rows_condition"
+}
+
+func (mqb *mockQueryBuilder) UnmaskQuery(role string, original string, anonymized string) string {
+	return fmt.Sprintf("unmask_query:%s;%s;%s", role, original, anonymized)
+}
+
+func (mqb *mockQueryBuilder) QuoteIdentifier(identifier string) string {
+	if plainIdentifierPattern.MatchString(identifier) && !mockKeywords[identifier] {
+		return identifier
+	}
+
+	return fmt.Sprintf(`"%s"`, strings.ReplaceAll(identifier, `"`, `""`))
+}
+
+func (mqb *mockQueryBuilder) QuoteLiteral(literal string) string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(literal, "'", "''"))
+}
+
+func (mqb *mockQueryBuilder) CreateViewQuery(viewName string, tableName string, columns []string) string {
+	return mqb.CreateFilteredViewQuery(viewName, tableName, columns)
+}
+
+func (mqb *mockQueryBuilder) CreateFilteredViewQuery(
 	viewName string,
 	tableName string,
 	columns []string,
+	conditions ...string,
 ) string {
 	columnsString := strings.Join(columns, "|")
 
+	if len(conditions) > 0 {
+		return fmt.Sprintf(
+			"create_view_query:%s;%s;%s;%s",
+			viewName,
+			tableName,
+			columnsString,
+			strings.Join(conditions, "&"),
+		)
+	}
+
 	return fmt.Sprintf("create_view_query:%s;%s;%s", viewName, tableName, columnsString)
 }
+
+func (mqb *mockQueryBuilder) CreateMaterializedViewQuery(
+	viewName string,
+	tableName string,
+	columns []string,
+	conditions ...string,
+) string {
+	return "create_materialized_" + strings.TrimPrefix(
+		mqb.CreateFilteredViewQuery(viewName, tableName, columns, conditions...),
+		"create_",
+	)
+}
+
+func (mqb *mockQueryBuilder) CreateUniqueIndexQuery(indexName string, viewName string, columns []string) string {
+	return fmt.Sprintf("create_unique_index_query:%s;%s;%s", indexName, viewName, strings.Join(columns, "|"))
+}
+
+func (mqb *mockQueryBuilder) RefreshMaterializedViewQuery(viewName string, concurrently bool) string {
+	return fmt.Sprintf("refresh_materialized_view_query:%s;%t", viewName, concurrently)
+}
+
+// versionCheckingQueryBuilder is a mockQueryBuilder implementing the ServerVersionChecker interface.
+type versionCheckingQueryBuilder struct {
+	mockQueryBuilder
+	minimumVersion string
+}
+
+func (mqb *versionCheckingQueryBuilder) ServerVersionQuery() string {
+	return "server_version_query"
+}
+
+func (mqb *versionCheckingQueryBuilder) CheckServerVersion(version string) error {
+	if version < mqb.minimumVersion {
+		return fmt.Errorf("Unsupported server version %s", version)
+	}
+
+	return nil
+}
+
+// minimalQueryBuilder implements the QueryBuilder interface without any of the optional interfaces.
+type minimalQueryBuilder struct {
+	mock *mockQueryBuilder
+}
+
+func (mqb *minimalQueryBuilder) ListViewsQuery() string {
+	return mqb.mock.ListViewsQuery()
+}
+
+func (mqb *minimalQueryBuilder) DropViewQuery(viewName string) string {
+	return mqb.mock.DropViewQuery(viewName)
+}
+
+func (mqb *minimalQueryBuilder) ListTablesQuery() string {
+	return mqb.mock.ListTablesQuery()
+}
+
+func (mqb *minimalQueryBuilder) ListColumnsQuery() string {
+	return mqb.mock.ListColumnsQuery()
+}
+
+func (mqb *minimalQueryBuilder) CreateViewQuery(viewName string, tableName string, columns []string) string {
+	return mqb.mock.CreateViewQuery(viewName, tableName, columns)
+}
+
+func (mqb *minimalQueryBuilder) QuoteIdentifier(identifier string) string {
+	return mqb.mock.QuoteIdentifier(identifier)
+}
+
+func (mqb *minimalQueryBuilder) QuoteLiteral(literal string) string {
+	return mqb.mock.QuoteLiteral(literal)
+}
+
+func TestGeneratorPlanSnapshotWithMinimalQueryBuilder(t *testing.T) {
+	snapshot := &Snapshot{
+		Tables: []SnapshotTable{
+			{
+				Schema:  "public",
+				Name:    "foo",
+				Columns: []Column{{Name: "id", DataType: "integer", OrdinalPosition: 1}},
+			},
+		},
+	}
+
+	cases := []struct {
+		title   string
+		options []GeneratorOption
+		table   *Table
+
+		expectedQuery string
+		expectedError error
+	}{
+		{
+			title: "without optional features",
+			table: NewTable().AddAnonymizer("id", NewStaticAnonymizer("0", "integer")),
+
+			expectedQuery: "create_view_query:foo_anonymized;foo;'0'::integer AS id",
+		},
+		{
+			title:   "with type preserving casts",
+			options: []GeneratorOption{WithTypePreservingCasts()},
+			table:   NewTable().AddAnonymizer("id", NewStaticAnonymizer("0", "")),
+
+			expectedError: errors.New(
+				"QueryBuilder does not implement gotidus.CastQueryBuilder required for type preserving casts",
+			),
+		},
+		{
+			title: "with row filter",
+			table: NewTable().WithRowFilter(NewSQLRowFilter("id > 0")),
+
+			expectedError: errors.New("QueryBuilder does not implement gotidus.FilteredViewQueryBuilder required for row filters"),
+		},
+		{
+			title: "with structure only table",
+			table: NewTable().StructureOnly(),
+
+			expectedError: errors.New(
+				"QueryBuilder does not implement gotidus.FilteredViewQueryBuilder required for structure only tables",
+			),
+		},
+		{
+			title:   "with unmask role",
+			options: []GeneratorOption{WithUnmaskRole("support")},
+			table:   NewTable().AddAnonymizer("id", NewStaticAnonymizer("0", "integer")),
+
+			expectedError: errors.New("QueryBuilder does not implement gotidus.UnmaskQueryBuilder required for unmask roles"),
+		},
+		{
+			title:   "with grantees",
+			options: []GeneratorOption{WithGrantees("analytics")},
+			table:   NewTable(),
+
+			expectedError: errors.New("QueryBuilder does not implement gotidus.GrantQueryBuilder required for grantees"),
+		},
+		{
+			title:   "with materialized views",
+			options: []GeneratorOption{WithMaterializedViews()},
+			table:   NewTable(),
+
+			expectedError: errors.New(
+				"QueryBuilder does not implement gotidus.MaterializedViewQueryBuilder required for materialized views",
+			),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			generator := NewGenerator(&minimalQueryBuilder{mock: &mockQueryBuilder{}}, c.options...)
+			generator.AddTable("foo", c.table)
+
+			plan, err := generator.PlanSnapshot(snapshot)
+
+			testutils.CompareStructs(err, c.expectedError, t)
+
+			if err == nil {
+				testutils.CompareStrings(plan.Statements[0].Query, c.expectedQuery, t)
+			}
+		})
+	}
+}
+
+func TestGeneratorClearViewsWithMinimalQueryBuilder(t *testing.T) {
+	queryBuilder := &minimalQueryBuilder{mock: &mockQueryBuilder{}}
+
+	db, dbMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to initialize DB mock")
+	}
+
+	// Without support for materialized views, only the names of the views are listed.
+	rows := sqlmock.NewRows([]string{"viewname"})
+	rows.AddRow("foo_anonymized")
+
+	dbMock.ExpectBegin()
+
+	dbMock.
+		ExpectQuery(queryBuilder.ListViewsQuery()).
+		WithArgs("", "anonymized").
+		WillReturnRows(rows)
+
+	dbMock.
+		ExpectExec("drop_view_query:foo_anonymized").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	dbMock.ExpectCommit()
+
+	generator := NewGenerator(queryBuilder)
+
+	testutils.CompareStructs(generator.ClearViews(db), nil, t)
+
+	if err := dbMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Did not execute expected queries: %+v", err)
+	}
+}
+
+func TestGeneratorPlanSyncWithMinimalQueryBuilder(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to initialize DB mock")
+	}
+
+	generator := NewGenerator(&minimalQueryBuilder{mock: &mockQueryBuilder{}})
+
+	_, _, err = generator.PlanSync(context.Background(), db)
+
+	testutils.CompareStructs(
+		err,
+		errors.New("QueryBuilder does not implement gotidus.SyncQueryBuilder required for syncing views"),
+		t,
+	)
+}
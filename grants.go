@@ -0,0 +1,160 @@
+package gotidus
+
+import (
+	"context"
+	"fmt"
+)
+
+// PublicGrantee is the grantee standing for all roles.
+// It is rendered as keyword instead of being quoted as role name.
+const PublicGrantee = "PUBLIC"
+
+// viewGrantees maps the existing views to the roles having been granted SELECT on them.
+type viewGrantees map[objectName][]string
+
+// existingGrantees reads the roles having been granted SELECT on the existing views.
+// The grantees are only read if grantees are configured, as grants are not managed otherwise.
+func (g *Generator) existingGrantees(ctx context.Context, q Querier) (viewGrantees, error) {
+	grantees := make(viewGrantees)
+
+	if len(g.grantees) == 0 {
+		return grantees, nil
+	}
+
+	queryBuilder, err := g.grantQueryBuilder()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, schema := range g.viewSchemas() {
+		rows, err := q.QueryContext(
+			ctx,
+			queryBuilder.ListViewGranteesQuery(),
+			schema,
+			g.listedViewPostfix(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to select view grantees: %+v", err)
+		}
+
+		for rows.Next() {
+			var viewName, grantee string
+
+			if err := rows.Scan(&viewName, &grantee); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("Failed to scan view grantee: %+v", err)
+			}
+
+			view := objectName{schema: schema, name: viewName}
+			grantees[view] = append(grantees[view], grantee)
+		}
+
+		if err := closeRows(rows); err != nil {
+			return nil, fmt.Errorf("Failed to select view grantees: %+v", err)
+		}
+	}
+
+	return grantees, nil
+}
+
+// planGrants adds the statements granting SELECT on the view to the configured grantees
+// and revoking it from all other roles given as existing grantees.
+// If configured, SELECT on the table of the view is revoked from the grantees as well.
+func (g *Generator) planGrants(plan *Plan, definition viewDefinition, existingGrantees []string) error {
+	if len(g.grantees) == 0 {
+		return nil
+	}
+
+	queryBuilder, err := g.grantQueryBuilder()
+	if err != nil {
+		return err
+	}
+
+	tableName := definition.table.String()
+	viewName := definition.view.String()
+
+	if stale := subtractRoles(existingGrantees, g.grantees); len(stale) > 0 {
+		plan.add(
+			StatementRevokeSelect,
+			tableName,
+			viewName,
+			queryBuilder.RevokeSelectQuery(g.quoteName(definition.view), g.quoteRoles(stale)),
+		)
+	}
+
+	if missing := subtractRoles(g.grantees, existingGrantees); len(missing) > 0 {
+		plan.add(
+			StatementGrantSelect,
+			tableName,
+			viewName,
+			queryBuilder.GrantSelectQuery(g.quoteName(definition.view), g.quoteRoles(missing)),
+		)
+	}
+
+	if g.revokeBaseTables {
+		plan.add(
+			StatementRevokeTableSelect,
+			tableName,
+			viewName,
+			queryBuilder.RevokeSelectQuery(g.quoteName(definition.table), g.quoteRoles(g.grantees)),
+		)
+	}
+
+	return nil
+}
+
+// grantQueryBuilder returns the QueryBuilder if it supports managing grants.
+func (g *Generator) grantQueryBuilder() (GrantQueryBuilder, error) {
+	queryBuilder, ok := g.queryBuilder.(GrantQueryBuilder)
+	if !ok {
+		return nil, unsupportedError("GrantQueryBuilder", "grantees")
+	}
+
+	return queryBuilder, nil
+}
+
+// quoteRoles quotes the role names using the QueryBuilder, apart from PublicGrantee.
+func (g *Generator) quoteRoles(roles []string) []string {
+	quoted := make([]string, 0, len(roles))
+	for _, role := range roles {
+		if role == PublicGrantee {
+			quoted = append(quoted, role)
+			continue
+		}
+
+		quoted = append(quoted, g.queryBuilder.QuoteIdentifier(role))
+	}
+
+	return quoted
+}
+
+// quoteIdentifiers quotes the identifiers using the QueryBuilder.
+func (g *Generator) quoteIdentifiers(identifiers []string) []string {
+	quoted := make([]string, 0, len(identifiers))
+	for _, identifier := range identifiers {
+		quoted = append(quoted, g.queryBuilder.QuoteIdentifier(identifier))
+	}
+
+	return quoted
+}
+
+// subtractRoles returns the roles which are not contained in the roles to subtract.
+func subtractRoles(roles []string, subtract []string) []string {
+	subtracted := make([]string, 0)
+
+	for _, role := range roles {
+		found := false
+		for _, other := range subtract {
+			if role == other {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			subtracted = append(subtracted, role)
+		}
+	}
+
+	return subtracted
+}
@@ -0,0 +1,304 @@
+package gotidus
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/viafintech/gotidus/testutils"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestGeneratorCreateViewsWithGrantees(t *testing.T) {
+	queryBuilder := &mockQueryBuilder{}
+
+	setupIntrospection := func(mock sqlmock.Sqlmock) {
+		granteeRows := sqlmock.NewRows([]string{"relname", "rolname"})
+		granteeRows.AddRow("foo_anonymized", "analytics")
+		granteeRows.AddRow("foo_anonymized", "legacy")
+		granteeRows.AddRow("foo_anonymized", PublicGrantee)
+
+		mock.
+			ExpectQuery(queryBuilder.ListViewsQuery()).
+			WithArgs("", "anonymized").
+			WillReturnRows(sqlmock.NewRows([]string{"viewname", "materialized"}))
+
+		mock.
+			ExpectQuery(queryBuilder.ListViewGranteesQuery()).
+			WithArgs("", "anonymized").
+			WillReturnRows(granteeRows)
+
+		tableRows := sqlmock.NewRows([]string{"schemaname", "tablename"})
+		tableRows.AddRow("public", "bar")
+		tableRows.AddRow("public", "foo")
+
+		mock.
+			ExpectQuery(queryBuilder.ListTablesQuery()).
+			WithArgs("").
+			WillReturnRows(tableRows)
+
+		for _, tableName := range []string{"bar", "foo"} {
+			columnRows := newColumnRows()
+			columnRows.AddRow("id", "integer", 1, false, nil, nil, nil, nil, nil, nil)
+
+			mock.
+				ExpectQuery(queryBuilder.ListColumnsQuery()).
+				WithArgs("public", tableName).
+				WillReturnRows(columnRows)
+		}
+	}
+
+	cases := []struct {
+		title         string
+		setupMock     func(sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			title: "grantee selection fails",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+
+				mock.
+					ExpectQuery(queryBuilder.ListViewsQuery()).
+					WithArgs("", "anonymized").
+					WillReturnRows(sqlmock.NewRows([]string{"viewname", "materialized"}))
+
+				mock.
+					ExpectQuery(queryBuilder.ListViewGranteesQuery()).
+					WithArgs("", "anonymized").
+					WillReturnError(errors.New("simulated failure"))
+
+				mock.ExpectRollback()
+			},
+			expectedError: errors.New("Failed to select view grantees: simulated failure"),
+		},
+		{
+			title: "granting fails",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+
+				setupIntrospection(mock)
+
+				mock.
+					ExpectExec("create_view_query:bar_anonymized").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+
+				mock.
+					ExpectExec("grant_select_query:bar_anonymized;analytics\\|support").
+					WillReturnError(errors.New("simulated failure"))
+
+				mock.ExpectRollback()
+			},
+			expectedError: errors.New("Failed to grant select on view 'bar_anonymized': simulated failure"),
+		},
+		{
+			title: "grants and revokes succeed",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+
+				setupIntrospection(mock)
+
+				mock.
+					ExpectExec("create_view_query:bar_anonymized").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+
+				mock.
+					ExpectExec("grant_select_query:bar_anonymized;analytics\\|support").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+
+				mock.
+					ExpectExec("revoke_select_query:bar;analytics\\|support").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+
+				mock.
+					ExpectExec("create_view_query:foo_anonymized").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+
+				mock.
+					ExpectExec("revoke_select_query:foo_anonymized;legacy\\|PUBLIC").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+
+				mock.
+					ExpectExec("grant_select_query:foo_anonymized;support").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+
+				mock.
+					ExpectExec("revoke_select_query:foo;analytics\\|support").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+
+				mock.ExpectCommit()
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to initialize DB mock")
+			}
+
+			c.setupMock(dbMock)
+
+			generator := NewGenerator(
+				queryBuilder,
+				WithGrantees("analytics", "support"),
+				WithRevokeBaseTables(),
+			)
+
+			testutils.CompareStructs(
+				generator.CreateViewsContext(context.Background(), db),
+				c.expectedError,
+				t,
+			)
+
+			if err := dbMock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Did not execute expected queries: %+v", err)
+			}
+		})
+	}
+}
+
+func TestGeneratorPlanSnapshotWithGrantees(t *testing.T) {
+	snapshot := &Snapshot{
+		Tables: []SnapshotTable{
+			{
+				Schema:  "billing",
+				Name:    "Invoices",
+				Columns: []Column{{Name: "id", DataType: "integer", OrdinalPosition: 1}},
+			},
+		},
+	}
+
+	generator := NewGenerator(
+		&mockQueryBuilder{},
+		WithSourceSchemas("billing"),
+		WithGrantees("analytics", "Support"),
+	)
+
+	plan, err := generator.PlanSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("Failed to build plan: %+v", err)
+	}
+
+	expectedStatements := []Statement{
+		{
+			Kind:      StatementCreateView,
+			TableName: "billing.Invoices",
+			ViewName:  "billing.Invoices_anonymized",
+			Query: "create_view_query:billing.\"Invoices_anonymized\";billing.\"Invoices\";" +
+				"\"Invoices\".id AS id",
+		},
+		{
+			Kind:      StatementGrantSelect,
+			TableName: "billing.Invoices",
+			ViewName:  "billing.Invoices_anonymized",
+			Query:     "grant_select_query:billing.\"Invoices_anonymized\";analytics|\"Support\"",
+		},
+	}
+
+	testutils.CompareStructs(plan.Statements, expectedStatements, t)
+}
+
+func TestGeneratorPlanSyncWithGrantees(t *testing.T) {
+	queryBuilder := &mockQueryBuilder{}
+
+	db, dbMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to initialize DB mock")
+	}
+
+	fooQuery := queryBuilder.CreateViewQuery("foo_anonymized", "foo", []string{"foo.id AS id"})
+	barQuery := queryBuilder.CreateViewQuery("bar_anonymized", "bar", []string{"bar.id AS id"})
+
+	viewRows := sqlmock.NewRows([]string{"relname", "obj_description", "materialized"})
+	viewRows.AddRow("foo_anonymized", fingerprint(fooQuery), false)
+
+	dbMock.
+		ExpectQuery(queryBuilder.ListViewFingerprintsQuery()).
+		WithArgs("", "anonymized").
+		WillReturnRows(viewRows)
+
+	granteeRows := sqlmock.NewRows([]string{"relname", "rolname"})
+	granteeRows.AddRow("foo_anonymized", "legacy")
+
+	dbMock.
+		ExpectQuery(queryBuilder.ListViewGranteesQuery()).
+		WithArgs("", "anonymized").
+		WillReturnRows(granteeRows)
+
+	tableRows := sqlmock.NewRows([]string{"schemaname", "tablename"})
+	tableRows.AddRow("public", "bar")
+	tableRows.AddRow("public", "foo")
+
+	dbMock.
+		ExpectQuery(queryBuilder.ListTablesQuery()).
+		WithArgs("").
+		WillReturnRows(tableRows)
+
+	for _, tableName := range []string{"bar", "foo"} {
+		columnRows := newColumnRows()
+		columnRows.AddRow("id", "integer", 1, false, nil, nil, nil, nil, nil, nil)
+
+		dbMock.
+			ExpectQuery(queryBuilder.ListColumnsQuery()).
+			WithArgs("public", tableName).
+			WillReturnRows(columnRows)
+	}
+
+	generator := NewGenerator(queryBuilder, WithGrantees("analytics"))
+
+	plan, report, err := generator.PlanSync(context.Background(), db)
+	if err != nil {
+		t.Fatalf("Failed to build plan: %+v", err)
+	}
+
+	// Changing the grantees does not replace the unchanged view.
+	expectedStatements := []Statement{
+		{
+			Kind:      StatementCreateView,
+			TableName: "bar",
+			ViewName:  "bar_anonymized",
+			Query:     barQuery,
+		},
+		{
+			Kind:      StatementCommentView,
+			TableName: "bar",
+			ViewName:  "bar_anonymized",
+			Query:     queryBuilder.CommentViewQuery("bar_anonymized", fingerprint(barQuery)),
+		},
+		{
+			Kind:      StatementGrantSelect,
+			TableName: "bar",
+			ViewName:  "bar_anonymized",
+			Query:     "grant_select_query:bar_anonymized;analytics",
+		},
+		{
+			Kind:      StatementRevokeSelect,
+			TableName: "foo",
+			ViewName:  "foo_anonymized",
+			Query:     "revoke_select_query:foo_anonymized;legacy",
+		},
+		{
+			Kind:      StatementGrantSelect,
+			TableName: "foo",
+			ViewName:  "foo_anonymized",
+			Query:     "grant_select_query:foo_anonymized;analytics",
+		},
+	}
+
+	testutils.CompareStructs(plan.Statements, expectedStatements, t)
+
+	expectedReport := &SyncReport{
+		Created:   []string{"bar_anonymized"},
+		Replaced:  []string{},
+		Dropped:   []string{},
+		Unchanged: []string{"foo_anonymized"},
+	}
+
+	testutils.CompareStructs(report, expectedReport, t)
+
+	if err := dbMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Did not execute expected queries: %+v", err)
+	}
+}
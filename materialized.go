@@ -0,0 +1,58 @@
+package gotidus
+
+import (
+	"context"
+	"fmt"
+)
+
+// RefreshViews refreshes the existing materialized views with the configured postfix,
+// which replaces their contents with the current data of their tables.
+// Refreshing concurrently does not block reads of the views, but requires a unique index
+// configured through Table.WithUniqueKey.
+func (g *Generator) RefreshViews(ctx context.Context, db Querier, concurrently bool) error {
+	return inTransaction(
+		ctx,
+		db,
+		func(q Querier) error {
+			plan := NewPlan()
+
+			if err := g.planRefreshViews(ctx, q, plan, concurrently); err != nil {
+				return err
+			}
+
+			return plan.apply(ctx, q)
+		},
+	)
+}
+
+func (g *Generator) planRefreshViews(ctx context.Context, q Querier, plan *Plan, concurrently bool) error {
+	queryBuilder, ok := g.queryBuilder.(MaterializedViewQueryBuilder)
+	if !ok {
+		return unsupportedError("MaterializedViewQueryBuilder", "materialized views")
+	}
+
+	return g.loopExistingViews(
+		ctx,
+		q,
+		func(view existingView) error {
+			if !view.materialized {
+				return nil
+			}
+
+			plan.add(
+				StatementRefreshMaterializedView,
+				g.tableName(view.name).String(),
+				view.name.String(),
+				queryBuilder.RefreshMaterializedViewQuery(g.quoteName(view.name), concurrently),
+			)
+
+			return nil
+		},
+	)
+}
+
+// uniqueIndexName builds the name of the unique index of the materialized view to <view_name>_key.
+// The index is created in the schema of the view, so its name is not qualified with the schema.
+func uniqueIndexName(view objectName) string {
+	return fmt.Sprintf("%s_key", view.name)
+}
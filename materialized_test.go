@@ -0,0 +1,415 @@
+package gotidus
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/viafintech/gotidus/testutils"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestGeneratorPlanSnapshotWithMaterializedViews(t *testing.T) {
+	snapshot := &Snapshot{
+		Tables: []SnapshotTable{
+			{
+				Schema: "public",
+				Name:   "events",
+				Columns: []Column{
+					{Name: "id", DataType: "integer", OrdinalPosition: 1},
+					{Name: "payload", DataType: "jsonb", OrdinalPosition: 2},
+				},
+			},
+			{
+				Schema:  "public",
+				Name:    "users",
+				Columns: []Column{{Name: "uid", DataType: "integer", OrdinalPosition: 1}},
+			},
+		},
+	}
+
+	generator := NewGenerator(&mockQueryBuilder{}, WithMaterializedViews())
+	generator.
+		AddTable("events", NewTable().AddAnonymizer("payload", NewStaticAnonymizer("{}", "jsonb"))).
+		AddTable("users", NewTable().RenameColumn("uid", "User ID").WithUniqueKey("User ID"))
+
+	plan, err := generator.PlanSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("Failed to build plan: %+v", err)
+	}
+
+	expectedStatements := []Statement{
+		{
+			Kind:      StatementDropMaterializedView,
+			TableName: "events",
+			ViewName:  "events_anonymized",
+			Query:     "drop_materialized_view_query:events_anonymized",
+		},
+		{
+			Kind:      StatementCreateMaterializedView,
+			TableName: "events",
+			ViewName:  "events_anonymized",
+			Query: "create_materialized_view_query:events_anonymized;events;" +
+				"events.id AS id|'{}'::jsonb AS payload",
+		},
+		{
+			Kind:      StatementDropMaterializedView,
+			TableName: "users",
+			ViewName:  "users_anonymized",
+			Query:     "drop_materialized_view_query:users_anonymized",
+		},
+		{
+			Kind:      StatementCreateMaterializedView,
+			TableName: "users",
+			ViewName:  "users_anonymized",
+			Query:     "create_materialized_view_query:users_anonymized;users;users.uid AS \"User ID\"",
+		},
+		{
+			Kind:      StatementCreateUniqueIndex,
+			TableName: "users",
+			ViewName:  "users_anonymized",
+			Query:     "create_unique_index_query:users_anonymized_key;users_anonymized;\"User ID\"",
+		},
+	}
+
+	testutils.CompareStructs(plan.Statements, expectedStatements, t)
+}
+
+func TestGeneratorPlanSnapshotWithMaterializedViewsAndUnmaskRole(t *testing.T) {
+	snapshot := &Snapshot{
+		Tables: []SnapshotTable{
+			{
+				Schema:  "public",
+				Name:    "users",
+				Columns: []Column{{Name: "email", DataType: "text", OrdinalPosition: 1}},
+			},
+		},
+	}
+
+	generator := NewGenerator(&mockQueryBuilder{}, WithMaterializedViews(), WithUnmaskRole("support"))
+	generator.AddTable("users", NewTable().AddAnonymizer("email", NewStaticAnonymizer("", "TEXT")))
+
+	_, err := generator.PlanSnapshot(snapshot)
+
+	testutils.CompareStructs(
+		err,
+		errors.New("Unmask role 'support' of column 'email' of table 'users' cannot be used with materialized views"),
+		t,
+	)
+}
+
+func TestGeneratorClearViewsWithMaterializedViews(t *testing.T) {
+	queryBuilder := &mockQueryBuilder{}
+
+	db, dbMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to initialize DB mock")
+	}
+
+	rows := sqlmock.NewRows([]string{"viewname", "materialized"})
+	rows.AddRow("bar_anonymized", false)
+	rows.AddRow("foo_anonymized", true)
+
+	dbMock.ExpectBegin()
+
+	dbMock.
+		ExpectQuery(queryBuilder.ListViewsQuery()).
+		WithArgs("", "anonymized").
+		WillReturnRows(rows)
+
+	dbMock.
+		ExpectExec("drop_view_query:bar_anonymized").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	dbMock.
+		ExpectExec("drop_materialized_view_query:foo_anonymized").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	dbMock.ExpectCommit()
+
+	generator := NewGenerator(queryBuilder)
+
+	testutils.CompareStructs(generator.ClearViews(db), nil, t)
+
+	if err := dbMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Did not execute expected queries: %+v", err)
+	}
+}
+
+func TestGeneratorRefreshViews(t *testing.T) {
+	queryBuilder := &mockQueryBuilder{}
+
+	setupViews := func(mock sqlmock.Sqlmock) {
+		rows := sqlmock.NewRows([]string{"viewname", "materialized"})
+		rows.AddRow("bar_anonymized", false)
+		rows.AddRow("baz_anonymized", true)
+		rows.AddRow("foo_anonymized", true)
+
+		mock.
+			ExpectQuery(queryBuilder.ListViewsQuery()).
+			WithArgs("", "anonymized").
+			WillReturnRows(rows)
+	}
+
+	cases := []struct {
+		title         string
+		concurrently  bool
+		setupMock     func(sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			title: "view selection fails",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+
+				mock.
+					ExpectQuery(queryBuilder.ListViewsQuery()).
+					WithArgs("", "anonymized").
+					WillReturnError(errors.New("simulated failure"))
+
+				mock.ExpectRollback()
+			},
+			expectedError: errors.New("Failed to select views: simulated failure"),
+		},
+		{
+			title: "refresh fails",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+
+				setupViews(mock)
+
+				mock.
+					ExpectExec("refresh_materialized_view_query:baz_anonymized;false").
+					WillReturnError(errors.New("simulated failure"))
+
+				mock.ExpectRollback()
+			},
+			expectedError: errors.New("Failed to refresh materialized view 'baz_anonymized': simulated failure"),
+		},
+		{
+			title:        "refresh concurrently succeeds",
+			concurrently: true,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+
+				setupViews(mock)
+
+				mock.
+					ExpectExec("refresh_materialized_view_query:baz_anonymized;true").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+
+				mock.
+					ExpectExec("refresh_materialized_view_query:foo_anonymized;true").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+
+				mock.ExpectCommit()
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to initialize DB mock")
+			}
+
+			c.setupMock(dbMock)
+
+			generator := NewGenerator(queryBuilder, WithMaterializedViews())
+
+			testutils.CompareStructs(
+				generator.RefreshViews(context.Background(), db, c.concurrently),
+				c.expectedError,
+				t,
+			)
+
+			if err := dbMock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Did not execute expected queries: %+v", err)
+			}
+		})
+	}
+}
+
+func TestGeneratorPlanSyncWithMaterializedViews(t *testing.T) {
+	queryBuilder := &mockQueryBuilder{}
+
+	db, dbMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to initialize DB mock")
+	}
+
+	viewRows := sqlmock.NewRows([]string{"relname", "obj_description", "materialized"})
+	viewRows.AddRow("foo_anonymized", nil, false)
+	viewRows.AddRow("stale_anonymized", nil, true)
+
+	dbMock.
+		ExpectQuery(queryBuilder.ListViewFingerprintsQuery()).
+		WithArgs("", "anonymized").
+		WillReturnRows(viewRows)
+
+	tableRows := sqlmock.NewRows([]string{"schemaname", "tablename"})
+	tableRows.AddRow("public", "foo")
+
+	dbMock.
+		ExpectQuery(queryBuilder.ListTablesQuery()).
+		WithArgs("").
+		WillReturnRows(tableRows)
+
+	columnRows := newColumnRows()
+	columnRows.AddRow("id", "integer", 1, false, nil, nil, nil, nil, nil, nil)
+
+	dbMock.
+		ExpectQuery(queryBuilder.ListColumnsQuery()).
+		WithArgs("public", "foo").
+		WillReturnRows(columnRows)
+
+	generator := NewGenerator(queryBuilder, WithMaterializedViews())
+	generator.AddTable("foo", NewTable().WithUniqueKey("id"))
+
+	plan, _, err := generator.PlanSync(context.Background(), db)
+	if err != nil {
+		t.Fatalf("Failed to build plan: %+v", err)
+	}
+
+	createQuery := "create_materialized_view_query:foo_anonymized;foo;foo.id AS id"
+	indexQuery := "create_unique_index_query:foo_anonymized_key;foo_anonymized;id"
+
+	expectedStatements := []Statement{
+		{
+			Kind:      StatementDropMaterializedView,
+			TableName: "stale",
+			ViewName:  "stale_anonymized",
+			Query:     "drop_materialized_view_query:stale_anonymized",
+		},
+		{
+			Kind:      StatementDropView,
+			TableName: "foo",
+			ViewName:  "foo_anonymized",
+			Query:     "drop_view_query:foo_anonymized",
+		},
+		{
+			Kind:      StatementCreateMaterializedView,
+			TableName: "foo",
+			ViewName:  "foo_anonymized",
+			Query:     createQuery,
+		},
+		{
+			Kind:      StatementCreateUniqueIndex,
+			TableName: "foo",
+			ViewName:  "foo_anonymized",
+			Query:     indexQuery,
+		},
+		{
+			Kind:      StatementCommentView,
+			TableName: "foo",
+			ViewName:  "foo_anonymized",
+			Query: "comment_materialized_view_query:foo_anonymized;" +
+				fingerprint(createQuery+indexQuery),
+		},
+	}
+
+	testutils.CompareStructs(plan.Statements, expectedStatements, t)
+
+	if err := dbMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Did not execute expected queries: %+v", err)
+	}
+}
+
+func TestGeneratorCreateViewsSwitchingViewKinds(t *testing.T) {
+	queryBuilder := &mockQueryBuilder{}
+
+	cases := []struct {
+		title                string
+		materialized         bool
+		existingMaterialized bool
+
+		expectedQueries []string
+	}{
+		{
+			title:                "view becomes materialized",
+			materialized:         true,
+			existingMaterialized: false,
+
+			expectedQueries: []string{
+				"drop_view_query:foo_anonymized",
+				"create_materialized_view_query:foo_anonymized",
+			},
+		},
+		{
+			title:                "materialized view becomes view",
+			materialized:         false,
+			existingMaterialized: true,
+
+			expectedQueries: []string{
+				"drop_materialized_view_query:foo_anonymized",
+				"create_view_query:foo_anonymized",
+			},
+		},
+		{
+			title:                "view stays view",
+			materialized:         false,
+			existingMaterialized: false,
+
+			expectedQueries: []string{
+				"create_view_query:foo_anonymized",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to initialize DB mock")
+			}
+
+			dbMock.ExpectBegin()
+
+			viewRows := sqlmock.NewRows([]string{"viewname", "materialized"})
+			viewRows.AddRow("foo_anonymized", c.existingMaterialized)
+
+			dbMock.
+				ExpectQuery(queryBuilder.ListViewsQuery()).
+				WithArgs("", "anonymized").
+				WillReturnRows(viewRows)
+
+			tableRows := sqlmock.NewRows([]string{"schemaname", "tablename"})
+			tableRows.AddRow("public", "foo")
+
+			dbMock.
+				ExpectQuery(queryBuilder.ListTablesQuery()).
+				WithArgs("").
+				WillReturnRows(tableRows)
+
+			columnRows := newColumnRows()
+			columnRows.AddRow("id", "integer", 1, false, nil, nil, nil, nil, nil, nil)
+
+			dbMock.
+				ExpectQuery(queryBuilder.ListColumnsQuery()).
+				WithArgs("public", "foo").
+				WillReturnRows(columnRows)
+
+			for _, query := range c.expectedQueries {
+				dbMock.
+					ExpectExec(query).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			}
+
+			dbMock.ExpectCommit()
+
+			options := []GeneratorOption{}
+			if c.materialized {
+				options = append(options, WithMaterializedViews())
+			}
+
+			generator := NewGenerator(queryBuilder, options...)
+
+			testutils.CompareStructs(generator.CreateViewsContext(context.Background(), db), nil, t)
+
+			if err := dbMock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Did not execute expected queries: %+v", err)
+			}
+		})
+	}
+}
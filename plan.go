@@ -0,0 +1,168 @@
+package gotidus
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// StatementKind describes the purpose of a Statement.
+type StatementKind string
+
+const (
+	// StatementDropView is the kind of a Statement removing a view.
+	StatementDropView StatementKind = "drop view"
+	// StatementCreateView is the kind of a Statement creating a view.
+	StatementCreateView StatementKind = "create view"
+	// StatementCommentView is the kind of a Statement storing the fingerprint of a view.
+	StatementCommentView StatementKind = "comment view"
+	// StatementDropMaterializedView is the kind of a Statement removing a materialized view.
+	StatementDropMaterializedView StatementKind = "drop materialized view"
+	// StatementCreateMaterializedView is the kind of a Statement creating a materialized view.
+	StatementCreateMaterializedView StatementKind = "create materialized view"
+	// StatementCreateUniqueIndex is the kind of a Statement creating the unique index of a materialized view.
+	StatementCreateUniqueIndex StatementKind = "create unique index on materialized view"
+	// StatementRefreshMaterializedView is the kind of a Statement refreshing a materialized view.
+	StatementRefreshMaterializedView StatementKind = "refresh materialized view"
+	// StatementGrantSelect is the kind of a Statement granting SELECT on a view.
+	StatementGrantSelect StatementKind = "grant select on view"
+	// StatementRevokeSelect is the kind of a Statement revoking stale grants of SELECT on a view.
+	StatementRevokeSelect StatementKind = "revoke select on view"
+	// StatementRevokeTableSelect is the kind of a Statement revoking SELECT on the table of a view.
+	StatementRevokeTableSelect StatementKind = "revoke select on table of view"
+)
+
+// Statement is a single query of a Plan along with the table and view it belongs to.
+// For statements creating a view, OmittedColumns lists the columns of the table
+// which are omitted from the view.
+type Statement struct {
+	Kind           StatementKind
+	TableName      string
+	ViewName       string
+	Query          string
+	OmittedColumns []string
+}
+
+// NewPlan initializes a new Plan object without any statements.
+func NewPlan() *Plan {
+	return &Plan{
+		Statements: make([]Statement, 0),
+	}
+}
+
+// Plan is the ordered list of statements which are required to bring the views up to date.
+// It is built by Generator.Plan and allows reviewing the statements before executing them.
+type Plan struct {
+	Statements []Statement
+}
+
+func (p *Plan) add(kind StatementKind, tableName, viewName, query string) {
+	p.Statements = append(
+		p.Statements,
+		Statement{
+			Kind:      kind,
+			TableName: tableName,
+			ViewName:  viewName,
+			Query:     query,
+		},
+	)
+}
+
+// addCreateView adds the statement creating the view of the definition
+// followed by the statement creating its unique index if configured.
+func (p *Plan) addCreateView(definition viewDefinition) {
+	kind := StatementCreateView
+	if definition.materialized {
+		kind = StatementCreateMaterializedView
+	}
+
+	p.Statements = append(
+		p.Statements,
+		Statement{
+			Kind:           kind,
+			TableName:      definition.table.String(),
+			ViewName:       definition.view.String(),
+			Query:          definition.query,
+			OmittedColumns: definition.omittedColumns,
+		},
+	)
+
+	if definition.indexQuery != "" {
+		p.add(
+			StatementCreateUniqueIndex,
+			definition.table.String(),
+			definition.view.String(),
+			definition.indexQuery,
+		)
+	}
+}
+
+// Apply executes the statements of the plan in order.
+func (p *Plan) Apply(ctx context.Context, db Querier) error {
+	return inTransaction(
+		ctx,
+		db,
+		func(q Querier) error {
+			return p.apply(ctx, q)
+		},
+	)
+}
+
+func (p *Plan) apply(ctx context.Context, q Querier) error {
+	for _, statement := range p.Statements {
+		if _, err := q.ExecContext(ctx, statement.Query); err != nil {
+			return fmt.Errorf("Failed to %s '%s': %+v", statement.Kind, statement.ViewName, err)
+		}
+	}
+
+	return nil
+}
+
+// WriteSQL renders the plan as an SQL script to the given writer.
+// Each statement is preceded by a comment naming its table and view as well as
+// the omitted columns, and the whole script is wrapped in a transaction.
+// Line breaks within the names are escaped, so that they cannot end the comment.
+func (p *Plan) WriteSQL(w io.Writer) error {
+	if _, err := io.WriteString(w, "BEGIN;\n\n"); err != nil {
+		return err
+	}
+
+	for _, statement := range p.Statements {
+		if _, err := fmt.Fprintf(
+			w,
+			"-- %s %s (table %s)\n",
+			statement.Kind,
+			commentSafe(statement.ViewName),
+			commentSafe(statement.TableName),
+		); err != nil {
+			return err
+		}
+
+		if len(statement.OmittedColumns) > 0 {
+			if _, err := fmt.Fprintf(
+				w,
+				"-- omitted columns: %s\n",
+				commentSafe(strings.Join(statement.OmittedColumns, ", ")),
+			); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "%s;\n\n", strings.TrimSpace(statement.Query)); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, "COMMIT;\n")
+
+	return err
+}
+
+// commentSafeReplacer escapes the characters ending a single line comment.
+var commentSafeReplacer = strings.NewReplacer("\\", "\\\\", "\r", "\\r", "\n", "\\n")
+
+// commentSafe escapes line breaks and backslashes of names written into single line comments.
+func commentSafe(name string) string {
+	return commentSafeReplacer.Replace(name)
+}
@@ -0,0 +1,225 @@
+package gotidus
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/viafintech/gotidus/testutils"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestGeneratorPlan(t *testing.T) {
+	queryBuilder := &mockQueryBuilder{}
+
+	db, dbMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to initialize DB mock")
+	}
+
+	viewRows := sqlmock.NewRows([]string{"viewname", "materialized"})
+	viewRows.AddRow("old_anonymized", false)
+
+	dbMock.
+		ExpectQuery(queryBuilder.ListViewsQuery()).
+		WithArgs("", "anonymized").
+		WillReturnRows(viewRows)
+
+	tableRows := sqlmock.NewRows([]string{"schemaname", "tablename"})
+	tableRows.AddRow("public", "foo")
+
+	dbMock.
+		ExpectQuery(queryBuilder.ListTablesQuery()).
+		WithArgs("").
+		WillReturnRows(tableRows)
+
+	columnRows := newColumnRows()
+	columnRows.AddRow("id", "integer", 1, false, nil, nil, nil, nil, nil, nil)
+	columnRows.AddRow("bar", "text", 2, false, nil, nil, nil, nil, nil, nil)
+
+	dbMock.
+		ExpectQuery(queryBuilder.ListColumnsQuery()).
+		WithArgs("public", "foo").
+		WillReturnRows(columnRows)
+
+	generator := NewGenerator(queryBuilder)
+	generator.AddTable("foo", NewTable().AddAnonymizer("bar", NewStaticAnonymizer("var", "TEXT")))
+
+	plan, err := generator.Plan(context.Background(), db)
+	if err != nil {
+		t.Fatalf("Failed to build plan: %+v", err)
+	}
+
+	expectedPlan := &Plan{
+		Statements: []Statement{
+			{
+				Kind:      StatementDropView,
+				TableName: "old",
+				ViewName:  "old_anonymized",
+				Query:     "drop_view_query:old_anonymized",
+			},
+			{
+				Kind:      StatementCreateView,
+				TableName: "foo",
+				ViewName:  "foo_anonymized",
+				Query:     "create_view_query:foo_anonymized;foo;foo.id AS id|'var'::TEXT AS bar",
+			},
+		},
+	}
+
+	testutils.CompareStructs(plan, expectedPlan, t)
+
+	if err := dbMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Did not execute expected queries")
+	}
+}
+
+func TestPlanApply(t *testing.T) {
+	plan := NewPlan()
+	plan.add(StatementDropView, "foo", "foo_anonymized", "drop_view_query:foo_anonymized")
+	plan.add(StatementCreateView, "foo", "foo_anonymized", "create_view_query:foo_anonymized")
+
+	cases := []struct {
+		title         string
+		setupMock     func(sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			title: "statement fails",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+
+				mock.
+					ExpectExec("drop_view_query:foo_anonymized").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.
+					ExpectExec("create_view_query:foo_anonymized").
+					WillReturnError(errors.New("simulated failure"))
+
+				mock.ExpectRollback()
+			},
+			expectedError: errors.New("Failed to create view 'foo_anonymized': simulated failure"),
+		},
+		{
+			title: "statements succeed",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+
+				mock.
+					ExpectExec("drop_view_query:foo_anonymized").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.
+					ExpectExec("create_view_query:foo_anonymized").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectCommit()
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to initialize DB mock")
+			}
+
+			c.setupMock(dbMock)
+
+			testutils.CompareStructs(
+				plan.Apply(context.Background(), db),
+				c.expectedError,
+				t,
+			)
+
+			if err := dbMock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Did not execute expected queries")
+			}
+		})
+	}
+}
+
+func TestPlanWriteSQL(t *testing.T) {
+	plan := NewPlan()
+	plan.add(StatementDropView, "foo", "foo_anonymized", "DROP VIEW IF EXISTS foo_anonymized")
+	plan.addCreateView(
+		viewDefinition{
+			table:          objectName{name: "foo"},
+			view:           objectName{name: "foo_anonymized"},
+			query:          "\n  CREATE OR REPLACE VIEW foo_anonymized AS\n    SELECT foo.id AS id\n    FROM foo",
+			omittedColumns: []string{"scan", "secret"},
+		},
+	)
+
+	var buf bytes.Buffer
+
+	if err := plan.WriteSQL(&buf); err != nil {
+		t.Fatalf("Failed to write plan: %+v", err)
+	}
+
+	testutils.CompareStrings(
+		buf.String(),
+		`BEGIN;
+
+-- drop view foo_anonymized (table foo)
+DROP VIEW IF EXISTS foo_anonymized;
+
+-- create view foo_anonymized (table foo)
+-- omitted columns: scan, secret
+CREATE OR REPLACE VIEW foo_anonymized AS
+    SELECT foo.id AS id
+    FROM foo;
+
+COMMIT;
+`,
+		t,
+	)
+}
+
+func TestPlanWriteSQLEscapesLineBreaksInNames(t *testing.T) {
+	snapshot := &Snapshot{
+		Tables: []SnapshotTable{
+			{
+				Schema: "public",
+				Name:   "x\nDROP TABLE users; --",
+				Columns: []Column{
+					{Name: "id", DataType: "integer", OrdinalPosition: 1},
+					{Name: "scan\r\nDROP TABLE accounts;", DataType: "bytea", OrdinalPosition: 2},
+				},
+			},
+		},
+	}
+
+	generator := NewGenerator(&mockQueryBuilder{})
+	generator.AddTable("x\nDROP TABLE users; --", NewTable().OmitColumn("scan\r\nDROP TABLE accounts;"))
+
+	plan, err := generator.PlanSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("Failed to build plan: %+v", err)
+	}
+
+	var buf bytes.Buffer
+
+	if err := plan.WriteSQL(&buf); err != nil {
+		t.Fatalf("Failed to write plan: %+v", err)
+	}
+
+	testutils.CompareStrings(
+		buf.String(),
+		`BEGIN;
+
+-- create view x\nDROP TABLE users; --_anonymized (table x\nDROP TABLE users; --)
+-- omitted columns: scan\r\nDROP TABLE accounts;
+create_view_query:"x
+DROP TABLE users; --_anonymized";"x
+DROP TABLE users; --";"x
+DROP TABLE users; --".id AS id;
+
+COMMIT;
+`,
+		t,
+	)
+}
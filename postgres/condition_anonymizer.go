@@ -16,7 +16,9 @@ type ConditionAnonymizer struct {
 	conditions        []AnonymizationCondition
 }
 
-// NewConditionAnonymizer initializes a new ConditionAnonymizer object
+// NewConditionAnonymizer initializes a new ConditionAnonymizer object.
+// If the data type is empty, the result is cast to the type of the column.
+// If the type of the column is unknown as well, the result is not cast.
 func NewConditionAnonymizer(
 	dataType string,
 	defaultAnonymizer gotidus.Anonymizer,
@@ -34,7 +36,24 @@ func NewConditionAnonymizer(
 // If one or more conditions are configured, they are defined as cases
 // in a CASE statement and the defaultAnonymizer covers the ELSE branch.
 func (a *ConditionAnonymizer) Build(tableName, columnName string) string {
-	defaultCase := a.defaultAnonymizer.Build(tableName, columnName)
+	return a.BuildColumn(tableName, gotidus.Column{Name: columnName})
+}
+
+// BuildColumn returns the same partial query as Build and passes the column metadata
+// on to the configured anonymizers.
+// If no data type was given on object initialization, the type of the column is used.
+func (a *ConditionAnonymizer) BuildColumn(tableName string, column gotidus.Column) string {
+	return a.BuildDialect(NewQueryBuilder(), tableName, column)
+}
+
+// BuildDialect returns the same partial query as BuildColumn
+// and passes the Quoter on to the configured anonymizers.
+func (a *ConditionAnonymizer) BuildDialect(
+	quoter gotidus.Quoter,
+	tableName string,
+	column gotidus.Column,
+) string {
+	defaultCase := gotidus.BuildDialect(quoter, a.defaultAnonymizer, tableName, column)
 
 	if len(a.conditions) < 1 {
 		return defaultCase
@@ -43,14 +62,17 @@ func (a *ConditionAnonymizer) Build(tableName, columnName string) string {
 	whenStrings := []string{}
 
 	for _, condition := range a.conditions {
-		whenStrings = append(whenStrings, condition.BuildCase(tableName, columnName))
+		whenStrings = append(whenStrings, condition.buildCase(quoter, tableName, column))
 	}
 
-	return fmt.Sprintf(
-		"(CASE %s ELSE %s END)::%s",
-		strings.Join(whenStrings, " "),
-		defaultCase,
-		a.dataType,
+	dataType := a.dataType
+	if dataType == "" {
+		dataType = column.TypeName(quoter)
+	}
+
+	return castValue(
+		fmt.Sprintf("(CASE %s ELSE %s END)", strings.Join(whenStrings, " "), defaultCase),
+		dataType,
 	)
 }
 
@@ -84,17 +106,56 @@ func NewAnonymizationCondition(
 func (ac AnonymizationCondition) BuildCase(
 	tableName string,
 	columnName string,
+) string {
+	return ac.buildCase(NewQueryBuilder(), tableName, gotidus.Column{Name: columnName})
+}
+
+func (ac AnonymizationCondition) buildCase(
+	quoter gotidus.Quoter,
+	tableName string,
+	column gotidus.Column,
 ) string {
 	return fmt.Sprintf(
-		"WHEN (%s) %s '%s'::%s THEN (%s)",
-		applyType(gotidus.FullColumnName(tableName, ac.column), ac.dataType),
-		ac.comparator,
-		ac.value,
-		ac.dataType,
-		ac.anonymizer.Build(tableName, columnName),
+		"WHEN %s THEN (%s)",
+		buildComparison(tableName, ac.column, ac.comparator, ac.value, ac.dataType),
+		gotidus.BuildDialect(quoter, ac.anonymizer, tableName, column),
 	)
 }
 
+// buildComparison builds the comparison of a column with a value, both cast to the given data type.
+// If the data type is empty, neither is cast.
+// The value is quoted with QuoteLiteral.
+func buildComparison(
+	tableName string,
+	column string,
+	comparator string,
+	value string,
+	dataType string,
+) string {
+	return fmt.Sprintf(
+		"(%s) %s %s",
+		applyType(FullColumnName(tableName, column), dataType),
+		comparator,
+		castValue(QuoteLiteral(value), dataType),
+	)
+}
+
+// applyType casts the parenthesized partial query to the given data type.
+// If the data type is empty, the partial query is returned unchanged.
 func applyType(partial string, dataType string) string {
+	if dataType == "" {
+		return partial
+	}
+
 	return fmt.Sprintf("(%s)::%s", partial, dataType)
 }
+
+// castValue casts the value or parenthesized expression to the given data type.
+// If the data type is empty, the value is returned unchanged.
+func castValue(value string, dataType string) string {
+	if dataType == "" {
+		return value
+	}
+
+	return fmt.Sprintf("%s::%s", value, dataType)
+}
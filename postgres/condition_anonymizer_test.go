@@ -58,6 +58,18 @@ func TestConditionAnonymizerBuild(t *testing.T) {
 				`WHEN ((foo.id)::integer) > '10'::integer THEN ('static'::TEXT) ` +
 				`ELSE 'static'::TEXT END)::TEXT`,
 		},
+		{
+			title:             "unknown data types",
+			dataType:          "",
+			defaultAnonymizer: gotidus.NewStaticAnonymizer("static", ""),
+			conditions: []AnonymizationCondition{
+				NewAnonymizationCondition("id", "=", "5", "", NewNullAnonymizer()),
+			},
+
+			expectedString: `(CASE ` +
+				`WHEN (foo.id) = '5' THEN (NULL::unknown) ` +
+				`ELSE 'static' END)`,
+		},
 	}
 
 	for _, c := range cases {
@@ -78,3 +90,18 @@ func TestConditionAnonymizerBuild(t *testing.T) {
 		})
 	}
 }
+
+func TestConditionAnonymizerBuildColumn(t *testing.T) {
+	anonymizer := NewConditionAnonymizer(
+		"",
+		gotidus.NewStaticAnonymizer("static", ""),
+		NewAnonymizationCondition("id", "=", "5", "integer", NewNullAnonymizer()),
+	)
+
+	testutils.CompareStrings(
+		anonymizer.BuildColumn("foo", gotidus.Column{Name: "bar", DataType: "text"}),
+		`(CASE WHEN ((foo.id)::integer) = '5'::integer THEN (NULL::text) `+
+			`ELSE 'static'::text END)::text`,
+		t,
+	)
+}
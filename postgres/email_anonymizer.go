@@ -2,8 +2,6 @@ package postgres
 
 import (
   "fmt"
-
-  "github.com/viafintech/gotidus"
 )
 
 // EmailAnonymizer is a gotidus.Anonymizer interface implementation,
@@ -70,7 +68,7 @@ func (a *EmailAnonymizer) Build(tableName, columnName string) string {
     )::CHARACTER VARYING
     ELSE %[1]s
     END`,
-    gotidus.FullColumnName(tableName, columnName),
+    FullColumnName(tableName, columnName),
     a.mailAnonymizedPartLength,
     a.domainPart(tableName, columnName),
   )
@@ -80,13 +78,13 @@ func (a *EmailAnonymizer) domainPart(tableName, columnName string) string {
   if a.mailAnonymizeDomainPart {
     return fmt.Sprintf(
       `("left"(md5(split_part((%[1]s)::text, '@'::text, 2)::text), %[2]d) || '.com')`,
-      gotidus.FullColumnName(tableName, columnName),
+      FullColumnName(tableName, columnName),
       a.mailAnonymizedPartLength,
     )
   }
 
   return fmt.Sprintf(
     `split_part((%s)::text, '@'::text, 2)`,
-    gotidus.FullColumnName(tableName, columnName),
+    FullColumnName(tableName, columnName),
   )
 }
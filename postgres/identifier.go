@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+)
+
+// QuoteIdentifier quotes the given identifier with double quotes if required.
+// Identifiers only consisting of lower case letters, digits and underscores,
+// which do not start with a digit and are not a keyword, are returned as is.
+// Double quotes within the identifier are escaped by doubling them.
+func QuoteIdentifier(identifier string) string {
+	if isPlainIdentifier(identifier) {
+		return identifier
+	}
+
+	return fmt.Sprintf(`"%s"`, strings.ReplaceAll(identifier, `"`, `""`))
+}
+
+// FullColumnName builds the full name of the column based on the table and column names.
+// Both names are quoted with QuoteIdentifier if required.
+func FullColumnName(tableName, columnName string) string {
+	return fmt.Sprintf("%s.%s", QuoteIdentifier(tableName), QuoteIdentifier(columnName))
+}
+
+func isPlainIdentifier(identifier string) bool {
+	if identifier == "" || keywords[identifier] {
+		return false
+	}
+
+	for i, r := range identifier {
+		switch {
+		case r >= 'a' && r <= 'z', r == '_':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// keywords holds the reserved and non-reserved (cannot be function or type) keywords
+// of PostgreSQL, which cannot be used as identifiers without quoting them.
+var keywords = map[string]bool{
+	"all": true, "analyse": true, "analyze": true, "and": true, "any": true, "array": true,
+	"as": true, "asc": true, "asymmetric": true, "authorization": true, "between": true,
+	"bigint": true, "binary": true, "bit": true, "boolean": true, "both": true, "case": true,
+	"cast": true, "char": true, "character": true, "check": true, "coalesce": true,
+	"collate": true, "collation": true, "column": true, "concurrently": true,
+	"constraint": true, "create": true, "cross": true, "current_catalog": true,
+	"current_date": true, "current_role": true, "current_schema": true, "current_time": true,
+	"current_timestamp": true, "current_user": true, "dec": true, "decimal": true,
+	"default": true, "deferrable": true, "desc": true, "distinct": true, "do": true,
+	"else": true, "end": true, "except": true, "exists": true, "extract": true, "false": true,
+	"fetch": true, "float": true, "for": true, "foreign": true, "freeze": true, "from": true,
+	"full": true, "grant": true, "greatest": true, "group": true, "grouping": true,
+	"having": true, "ilike": true, "in": true, "initially": true, "inner": true, "inout": true,
+	"int": true, "integer": true, "intersect": true, "interval": true, "into": true, "is": true,
+	"isnull": true, "join": true, "json": true, "lateral": true, "leading": true, "least": true,
+	"left": true, "like": true, "limit": true, "localtime": true, "localtimestamp": true,
+	"national": true, "natural": true, "nchar": true, "none": true, "normalize": true,
+	"not": true, "notnull": true, "null": true, "nullif": true, "numeric": true, "offset": true,
+	"on": true, "only": true, "or": true, "order": true, "out": true, "outer": true,
+	"overlaps": true, "overlay": true, "placing": true, "position": true, "precision": true,
+	"primary": true, "real": true, "references": true, "returning": true, "right": true,
+	"row": true, "select": true, "session_user": true, "setof": true, "similar": true,
+	"smallint": true, "some": true, "substring": true, "symmetric": true, "system_user": true,
+	"table": true, "tablesample": true, "then": true, "time": true, "timestamp": true,
+	"to": true, "trailing": true, "treat": true, "trim": true, "true": true, "union": true,
+	"unique": true, "user": true, "using": true, "values": true, "varchar": true,
+	"variadic": true, "verbose": true, "when": true, "where": true, "window": true,
+	"with": true, "xmlattributes": true, "xmlconcat": true, "xmlelement": true,
+	"xmlexists": true, "xmlforest": true, "xmlnamespaces": true, "xmlparse": true,
+	"xmlpi": true, "xmlroot": true, "xmlserialize": true, "xmltable": true,
+}
@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/viafintech/gotidus/testutils"
+)
+
+func TestQuoteIdentifier(t *testing.T) {
+	cases := []struct {
+		title      string
+		identifier string
+
+		expectedIdentifier string
+	}{
+		{
+			title:      "plain identifier",
+			identifier: "amount_2",
+
+			expectedIdentifier: "amount_2",
+		},
+		{
+			title:      "mixed case",
+			identifier: "createdAt",
+
+			expectedIdentifier: `"createdAt"`,
+		},
+		{
+			title:      "reserved word",
+			identifier: "user",
+
+			expectedIdentifier: `"user"`,
+		},
+		{
+			title:      "leading digit",
+			identifier: "2fa_secret",
+
+			expectedIdentifier: `"2fa_secret"`,
+		},
+		{
+			title:      "space",
+			identifier: "first name",
+
+			expectedIdentifier: `"first name"`,
+		},
+		{
+			title:      "double quote",
+			identifier: `a"b`,
+
+			expectedIdentifier: `"a""b"`,
+		},
+		{
+			title:      "empty",
+			identifier: "",
+
+			expectedIdentifier: `""`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			testutils.CompareStrings(QuoteIdentifier(c.identifier), c.expectedIdentifier, t)
+		})
+	}
+}
+
+func TestFullColumnName(t *testing.T) {
+	testutils.CompareStrings(FullColumnName("invoices", "amount"), "invoices.amount", t)
+	testutils.CompareStrings(FullColumnName("Invoices", "user"), `"Invoices"."user"`, t)
+}
@@ -0,0 +1,22 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+)
+
+// QuoteLiteral quotes the given value as PostgreSQL string literal.
+// Single quotes are escaped by doubling them. If the value contains backslashes,
+// they are doubled as well and the literal is written as escape string (E'...'),
+// so the value is interpreted the same regardless of the standard_conforming_strings setting.
+// NUL characters cannot be stored in PostgreSQL strings and are removed.
+func QuoteLiteral(literal string) string {
+	literal = strings.ReplaceAll(literal, "\x00", "")
+	literal = strings.ReplaceAll(literal, "'", "''")
+
+	if strings.Contains(literal, `\`) {
+		return fmt.Sprintf("E'%s'", strings.ReplaceAll(literal, `\`, `\\`))
+	}
+
+	return fmt.Sprintf("'%s'", literal)
+}
@@ -0,0 +1,269 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/viafintech/gotidus"
+	"github.com/viafintech/gotidus/testutils"
+)
+
+func TestQuoteLiteral(t *testing.T) {
+	cases := []struct {
+		title   string
+		literal string
+
+		expectedLiteral string
+	}{
+		{
+			title:   "plain value",
+			literal: "value",
+
+			expectedLiteral: `'value'`,
+		},
+		{
+			title:   "single quote",
+			literal: "it's",
+
+			expectedLiteral: `'it''s'`,
+		},
+		{
+			title:   "backslash",
+			literal: `\d+`,
+
+			expectedLiteral: `E'\\d+'`,
+		},
+		{
+			title:   "backslash before single quote",
+			literal: `\'; DROP TABLE users; --`,
+
+			expectedLiteral: `E'\\''; DROP TABLE users; --'`,
+		},
+		{
+			title:   "NUL character",
+			literal: "a\x00b",
+
+			expectedLiteral: `'ab'`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			testutils.CompareStrings(QuoteLiteral(c.literal), c.expectedLiteral, t)
+		})
+	}
+}
+
+func FuzzQuoteLiteral(f *testing.F) {
+	for _, seed := range []string{"", "value", "it's", `\`, `\'`, `''\\''`, "a\x00b"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, literal string) {
+		quoted := QuoteLiteral(literal)
+
+		skeleton, values, err := scanLiterals(quoted)
+		if err != nil {
+			t.Fatalf("Failed to scan %s: %+v", quoted, err)
+		}
+
+		if skeleton != "?" {
+			t.Fatalf("Expected a single literal, got %s from %s", skeleton, quoted)
+		}
+
+		testutils.CompareStrings(values[0], strings.ReplaceAll(literal, "\x00", ""), t)
+	})
+}
+
+// FuzzAnonymizerLiterals ensures that user provided values cannot escape
+// the string literals of the partial queries built by the anonymizers.
+// The structure of the query outside of the literals has to be the same
+// regardless of the value.
+func FuzzAnonymizerLiterals(f *testing.F) {
+	for _, seed := range []string{"value", "it's", `\'; DROP TABLE users; --`, `E'\\'`} {
+		f.Add(seed)
+	}
+
+	builders := map[string]func(value string) string{
+		"condition": func(value string) string {
+			return NewConditionAnonymizer(
+				"TEXT",
+				NewNullAnonymizer(),
+				NewAnonymizationCondition("id", "=", value, "TEXT", NewNullAnonymizer()),
+			).Build("foo", "bar")
+		},
+		"condition with static value": func(value string) string {
+			return NewConditionAnonymizer(
+				"TEXT",
+				gotidus.NewStaticAnonymizer(value, "TEXT"),
+				NewAnonymizationCondition("id", "=", "x", "TEXT", NewNullAnonymizer()),
+			).Build("foo", "bar")
+		},
+		"condition row filter": func(value string) string {
+			return NewConditionRowFilter("id", "=", value, "TEXT").Build("foo")
+		},
+		"overlay": func(value string) string {
+			return NewOverlayAnonymizer(value, 1, 2).Build("foo", "bar")
+		},
+		"regexp replace pattern": func(value string) string {
+			return NewRegexReplaceAnonymizer(value, "x").Build("foo", "bar")
+		},
+		"regexp replace replacement": func(value string) string {
+			return NewRegexReplaceAnonymizer("x", value).Build("foo", "bar")
+		},
+		"remove json keys": func(value string) string {
+			return NewRemoveJSONKeysAnonymizer([]string{value, "other"}).Build("foo", "bar")
+		},
+		"static": func(value string) string {
+			return gotidus.BuildDialect(
+				NewQueryBuilder(),
+				gotidus.NewStaticAnonymizer(value, "TEXT"),
+				"foo",
+				gotidus.Column{Name: "bar"},
+			)
+		},
+	}
+
+	f.Fuzz(func(t *testing.T, value string) {
+		for title, build := range builders {
+			expectedSkeleton, _, err := scanLiterals(build("x"))
+			if err != nil {
+				t.Fatalf("%s: Failed to scan reference query: %+v", title, err)
+			}
+
+			query := build(value)
+
+			skeleton, _, err := scanLiterals(query)
+			if err != nil {
+				t.Fatalf("%s: Failed to scan %s: %+v", title, query, err)
+			}
+
+			if skeleton != expectedSkeleton {
+				t.Fatalf("%s: Value escaped its literal:\n%s", title, query)
+			}
+		}
+
+		query := NewSHA256Anonymizer(5).Build(value, "bar")
+
+		skeleton, _, err := scanLiterals(query)
+		if err != nil {
+			t.Fatalf("sha256: Failed to scan %s: %+v", query, err)
+		}
+
+		testutils.CompareStrings(
+			skeleton,
+			fmt.Sprintf("SUBSTRING(ENCODE(DIGEST(%s::text, ?), ?), 0, 6)", FullColumnName(value, "bar")),
+			t,
+		)
+	})
+}
+
+// scanLiterals replaces every string literal of the query with a question mark
+// and returns the resulting query skeleton along with the values of the literals.
+// It supports standard string literals as well as escape string literals (E'...')
+// and skips quoted identifiers.
+func scanLiterals(query string) (string, []string, error) {
+	var skeleton strings.Builder
+
+	values := make([]string, 0)
+
+	for i := 0; i < len(query); i++ {
+		switch {
+		case query[i] == '"':
+			length, err := scanIdentifier(query[i:])
+			if err != nil {
+				return "", nil, err
+			}
+
+			skeleton.WriteString(query[i : i+length])
+			i += length - 1
+		case query[i] == '\'' || isEscapeStringStart(query, i):
+			escape := query[i] != '\''
+			if escape {
+				i++
+			}
+
+			value, length, err := scanLiteral(query[i:], escape)
+			if err != nil {
+				return "", nil, err
+			}
+
+			skeleton.WriteByte('?')
+			values = append(values, value)
+			i += length - 1
+		default:
+			skeleton.WriteByte(query[i])
+		}
+	}
+
+	return skeleton.String(), values, nil
+}
+
+// scanIdentifier returns the number of bytes the quoted identifier
+// at the start of the given string spans.
+func scanIdentifier(s string) (int, error) {
+	for i := 1; i < len(s); i++ {
+		if s[i] != '"' {
+			continue
+		}
+
+		if i+1 < len(s) && s[i+1] == '"' {
+			i++
+			continue
+		}
+
+		return i + 1, nil
+	}
+
+	return 0, fmt.Errorf("Unterminated identifier")
+}
+
+func isEscapeStringStart(query string, i int) bool {
+	if query[i] != 'E' && query[i] != 'e' {
+		return false
+	}
+
+	if i+1 >= len(query) || query[i+1] != '\'' {
+		return false
+	}
+
+	if i == 0 {
+		return true
+	}
+
+	previous := query[i-1]
+
+	return !(previous == '_' ||
+		(previous >= 'a' && previous <= 'z') ||
+		(previous >= 'A' && previous <= 'Z') ||
+		(previous >= '0' && previous <= '9'))
+}
+
+// scanLiteral reads the literal at the start of the given string,
+// which has to start with a single quote.
+// It returns the value of the literal and the number of bytes it spans.
+func scanLiteral(s string, escape bool) (string, int, error) {
+	var value strings.Builder
+
+	for i := 1; i < len(s); i++ {
+		switch {
+		case escape && s[i] == '\\':
+			if i+1 >= len(s) {
+				return "", 0, fmt.Errorf("Unterminated escape sequence")
+			}
+
+			i++
+			value.WriteByte(s[i])
+		case s[i] == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+			value.WriteByte('\'')
+		case s[i] == '\'':
+			return value.String(), i + 1, nil
+		default:
+			value.WriteByte(s[i])
+		}
+	}
+
+	return "", 0, fmt.Errorf("Unterminated literal")
+}
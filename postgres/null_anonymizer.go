@@ -1,7 +1,14 @@
 package postgres
 
+import (
+	"fmt"
+
+	"github.com/viafintech/gotidus"
+)
+
 // NullAnonymizer is a gotidus.Anonymizer interface implementation.
 // It overwrites any given value with NULL.
+// When used through the gotidus.Generator, the NULL is typed with the type of the column.
 type NullAnonymizer struct{}
 
 // NewNullAnonymizer initializes a new NullAnonymizer object.
@@ -13,3 +20,14 @@ func NewNullAnonymizer() *NullAnonymizer {
 func (a *NullAnonymizer) Build(tableName, columnName string) string {
 	return "NULL::unknown"
 }
+
+// BuildColumn returns NULL cast to the type of the column as partial query.
+// If the type of the column is unknown, it behaves like Build.
+func (a *NullAnonymizer) BuildColumn(tableName string, column gotidus.Column) string {
+	typeName := column.TypeName(NewQueryBuilder())
+	if typeName == "" {
+		return a.Build(tableName, column.Name)
+	}
+
+	return fmt.Sprintf("NULL::%s", typeName)
+}
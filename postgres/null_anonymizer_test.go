@@ -3,6 +3,7 @@ package postgres
 import (
 	"testing"
 
+	"github.com/viafintech/gotidus"
 	"github.com/viafintech/gotidus/testutils"
 )
 
@@ -18,3 +19,47 @@ func TestNullAnonymizerBuild(t *testing.T) {
 		t,
 	)
 }
+
+func TestNullAnonymizerBuildColumn(t *testing.T) {
+	cases := []struct {
+		title  string
+		column gotidus.Column
+
+		expectedString string
+	}{
+		{
+			title:  "builtin type",
+			column: gotidus.Column{Name: "bar", DataType: "integer", UDTName: "int4"},
+
+			expectedString: "NULL::integer",
+		},
+		{
+			title:  "array type",
+			column: gotidus.Column{Name: "bar", DataType: "ARRAY", UDTName: "_text"},
+
+			expectedString: "NULL::_text",
+		},
+		{
+			title:  "user-defined type",
+			column: gotidus.Column{Name: "bar", DataType: "USER-DEFINED", UDTName: "Mood"},
+
+			expectedString: `NULL::"Mood"`,
+		},
+		{
+			title:  "unknown type",
+			column: gotidus.Column{Name: "bar"},
+
+			expectedString: "NULL::unknown",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			testutils.CompareStrings(
+				NewNullAnonymizer().BuildColumn("foo", c.column),
+				c.expectedString,
+				t,
+			)
+		})
+	}
+}
@@ -3,8 +3,6 @@ package postgres
 import (
 	"fmt"
 	"strings"
-
-	"github.com/viafintech/gotidus"
 )
 
 // OverlayAnonymizer is a gotidus.Anonymizer implementation
@@ -32,9 +30,9 @@ func (a *OverlayAnonymizer) Build(tableName, columnName string) string {
 	overlay := strings.Repeat(a.overlayBase, a.count)
 
 	return fmt.Sprintf(
-		`"overlay"((%s)::text, '%s'::text, %d)`,
-		gotidus.FullColumnName(tableName, columnName),
-		overlay,
+		`"overlay"((%s)::text, %s::text, %d)`,
+		FullColumnName(tableName, columnName),
+		QuoteLiteral(overlay),
 		a.start,
 	)
 }
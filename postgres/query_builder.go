@@ -2,26 +2,48 @@ package postgres
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
 // QueryBuilder is the specific implementation of the gotidus.QueryBuilder interface for PostgreSQL.
-type QueryBuilder struct{}
+type QueryBuilder struct {
+	securityBarrier bool
+	securityInvoker bool
+}
 
 // NewQueryBuilder initializes a new QueryBuilder object.
-func NewQueryBuilder() *QueryBuilder {
-	return &QueryBuilder{}
+// It can be enhanced with QueryBuilderOption functions.
+func NewQueryBuilder(options ...QueryBuilderOption) *QueryBuilder {
+	queryBuilder := &QueryBuilder{}
+
+	for _, option := range options {
+		option(queryBuilder)
+	}
+
+	return queryBuilder
 }
 
 const listViewsQuery string = `
   SELECT
-    viewname
+    viewname,
+    false AS materialized
   FROM pg_catalog.pg_views
-  WHERE viewname ILIKE '%' || $1
+  WHERE schemaname = COALESCE(NULLIF($1, ''), CURRENT_SCHEMA)
+    AND viewname ILIKE '%' || $2
+  UNION ALL
+  SELECT
+    matviewname,
+    true
+  FROM pg_catalog.pg_matviews
+  WHERE schemaname = COALESCE(NULLIF($1, ''), CURRENT_SCHEMA)
+    AND matviewname ILIKE '%' || $2
   ORDER BY viewname ASC`
 
-// ListViewsQuery returns the query for listing existing views.
-// It requires passing the view postfix on query execution.
+// ListViewsQuery returns the query for listing existing views and materialized views
+// along with whether they are materialized.
+// It requires passing the schema and the view postfix on query execution.
+// An empty schema refers to the current schema.
 func (qb *QueryBuilder) ListViewsQuery() string {
 	return listViewsQuery
 }
@@ -29,30 +51,135 @@ func (qb *QueryBuilder) ListViewsQuery() string {
 const dropViewQueryTemplate string = "DROP VIEW IF EXISTS %s"
 
 // DropViewQuery returns the query for removing the view for which the name is given.
+// The view name is expected to be quoted through QuoteIdentifier already.
 func (qb *QueryBuilder) DropViewQuery(viewName string) string {
 	return fmt.Sprintf(dropViewQueryTemplate, viewName)
 }
 
+const dropMaterializedViewQueryTemplate string = "DROP MATERIALIZED VIEW IF EXISTS %s"
+
+// DropMaterializedViewQuery returns the query for removing the materialized view for which the name is given.
+// The view name is expected to be quoted through QuoteIdentifier already.
+func (qb *QueryBuilder) DropMaterializedViewQuery(viewName string) string {
+	return fmt.Sprintf(dropMaterializedViewQueryTemplate, viewName)
+}
+
+const listViewFingerprintsQuery string = `
+  SELECT
+    c.relname,
+    obj_description(c.oid, 'pg_class'),
+    c.relkind = 'm' AS materialized
+  FROM pg_catalog.pg_class c
+  JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+  WHERE c.relkind IN ('v', 'm')
+    AND n.nspname = COALESCE(NULLIF($1, ''), CURRENT_SCHEMA)
+    AND c.relname ILIKE '%' || $2
+  ORDER BY c.relname ASC`
+
+// ListViewFingerprintsQuery returns the query for listing existing views and materialized views
+// along with their comment, which holds the fingerprint of the view definition,
+// and whether they are materialized.
+// It requires passing the schema and the view postfix on query execution.
+// An empty schema refers to the current schema.
+func (qb *QueryBuilder) ListViewFingerprintsQuery() string {
+	return listViewFingerprintsQuery
+}
+
+const commentViewQueryTemplate string = "COMMENT ON VIEW %s IS %s"
+
+// CommentViewQuery returns the query for setting the comment of the view for which the name is given.
+// The view name is expected to be quoted through QuoteIdentifier already.
+func (qb *QueryBuilder) CommentViewQuery(viewName string, comment string) string {
+	return fmt.Sprintf(commentViewQueryTemplate, viewName, QuoteLiteral(comment))
+}
+
+const commentMaterializedViewQueryTemplate string = "COMMENT ON MATERIALIZED VIEW %s IS %s"
+
+// CommentMaterializedViewQuery returns the query for setting the comment of the materialized view
+// for which the name is given.
+// The view name is expected to be quoted through QuoteIdentifier already.
+func (qb *QueryBuilder) CommentMaterializedViewQuery(viewName string, comment string) string {
+	return fmt.Sprintf(commentMaterializedViewQueryTemplate, viewName, QuoteLiteral(comment))
+}
+
+const listViewGranteesQuery string = `
+  SELECT DISTINCT
+    c.relname,
+    COALESCE(r.rolname, 'PUBLIC') AS rolname
+  FROM pg_catalog.pg_class c
+  JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+  CROSS JOIN LATERAL aclexplode(c.relacl) a
+  LEFT JOIN pg_catalog.pg_roles r ON r.oid = a.grantee
+  WHERE c.relkind IN ('v', 'm')
+    AND n.nspname = COALESCE(NULLIF($1, ''), CURRENT_SCHEMA)
+    AND c.relname ILIKE '%' || $2
+    AND a.privilege_type = 'SELECT'
+    AND a.grantee <> c.relowner
+  ORDER BY c.relname ASC, rolname ASC`
+
+// ListViewGranteesQuery returns the query for listing the roles having been granted SELECT
+// on existing views and materialized views. The owner of the view is not listed,
+// while grants to PUBLIC are listed as gotidus.PublicGrantee.
+// It requires passing the schema and the view postfix on query execution.
+// An empty schema refers to the current schema.
+func (qb *QueryBuilder) ListViewGranteesQuery() string {
+	return listViewGranteesQuery
+}
+
+const grantSelectQueryTemplate string = "GRANT SELECT ON %s TO %s"
+
+// GrantSelectQuery returns the query for granting SELECT on the table or view to the given roles.
+// The object name and roles are expected to be quoted through QuoteIdentifier already.
+func (qb *QueryBuilder) GrantSelectQuery(objectName string, roles []string) string {
+	return fmt.Sprintf(grantSelectQueryTemplate, objectName, strings.Join(roles, ", "))
+}
+
+const revokeSelectQueryTemplate string = "REVOKE SELECT ON %s FROM %s"
+
+// RevokeSelectQuery returns the query for revoking SELECT on the table or view from the given roles.
+// The object name and roles are expected to be quoted through QuoteIdentifier already.
+func (qb *QueryBuilder) RevokeSelectQuery(objectName string, roles []string) string {
+	return fmt.Sprintf(revokeSelectQueryTemplate, objectName, strings.Join(roles, ", "))
+}
+
 const listTablesQuery string = `
-  SELECT tablename
+  SELECT schemaname, tablename
   FROM pg_catalog.pg_tables
-  WHERE schemaname = CURRENT_SCHEMA
+  WHERE schemaname = COALESCE(NULLIF($1, ''), CURRENT_SCHEMA)
   ORDER BY tablename ASC`
 
-// ListTablesQuery returns the query for listing existing tables.
+// ListTablesQuery returns the query for listing existing tables along with their schema.
+// It requires passing the schema on query execution.
+// An empty schema refers to the current schema.
 func (qb *QueryBuilder) ListTablesQuery() string {
 	return listTablesQuery
 }
 
 const listColumnsQuery string = `
   SELECT
-    column_name
-  FROM information_schema.columns
-  WHERE table_name = $1
-  ORDER BY ordinal_position ASC`
+    c.column_name,
+    c.data_type,
+    c.ordinal_position,
+    c.is_nullable = 'YES' AS is_nullable,
+    c.column_default,
+    c.character_maximum_length,
+    c.udt_name,
+    pg_catalog.format_type(a.atttypid, a.atttypmod) AS formatted_type,
+    t.typcategory::text AS type_category,
+    c.domain_name
+  FROM information_schema.columns c
+  LEFT JOIN pg_catalog.pg_attribute a
+    ON a.attrelid = (quote_ident(c.table_schema) || '.' || quote_ident(c.table_name))::regclass
+    AND a.attname = c.column_name
+  LEFT JOIN pg_catalog.pg_type t
+    ON t.oid = a.atttypid
+  WHERE c.table_schema = $1
+    AND c.table_name = $2
+  ORDER BY c.ordinal_position ASC`
 
 // ListColumnsQuery returns the query for listing existing columns.
-// It requires passing the table name on query execution for which the columns should be listed.
+// It requires passing the schema and table name on query execution
+// for which the columns should be listed.
 func (qb *QueryBuilder) ListColumnsQuery() string {
 	return listColumnsQuery
 }
@@ -62,19 +189,229 @@ const createViewQueryTemplate string = `
     SELECT %s
     FROM %s`
 
+const createViewConditionTemplate string = `
+    WHERE %s`
+
+const viewOptionsTemplate string = "%s WITH (%s)"
+
 // CreateViewQuery returns the query for creating a view.
 // It builds the query using the view name, table name and the data for the selectable columns.
-func (qb *QueryBuilder) CreateViewQuery(
+// The view and table names are expected to be quoted through QuoteIdentifier already.
+// The view options configured through QueryBuilderOption functions are added to the view.
+func (qb *QueryBuilder) CreateViewQuery(viewName string, tableName string, columns []string) string {
+	return qb.CreateFilteredViewQuery(viewName, tableName, columns)
+}
+
+// CreateFilteredViewQuery returns the query for creating a view in the same way as CreateViewQuery.
+// If conditions are given, only rows matching all of them are selected.
+func (qb *QueryBuilder) CreateFilteredViewQuery(
 	viewName string,
 	tableName string,
 	columns []string,
+	conditions ...string,
 ) string {
 	joinedSelectString := strings.Join(columns, ", ")
 
-	return fmt.Sprintf(
+	if viewOptions := qb.viewOptions(); len(viewOptions) > 0 {
+		viewName = fmt.Sprintf(viewOptionsTemplate, viewName, strings.Join(viewOptions, ", "))
+	}
+
+	query := fmt.Sprintf(
 		createViewQueryTemplate,
 		viewName,
 		joinedSelectString,
 		tableName,
 	)
+
+	return query + conditionQuery(conditions)
+}
+
+const createMaterializedViewQueryTemplate string = `
+  CREATE MATERIALIZED VIEW %s AS
+    SELECT %s
+    FROM %s`
+
+// CreateMaterializedViewQuery returns the query for creating a materialized view
+// in the same way as CreateViewQuery.
+// The view options configured through QueryBuilderOption functions do not apply to materialized views.
+func (qb *QueryBuilder) CreateMaterializedViewQuery(
+	viewName string,
+	tableName string,
+	columns []string,
+	conditions ...string,
+) string {
+	query := fmt.Sprintf(
+		createMaterializedViewQueryTemplate,
+		viewName,
+		strings.Join(columns, ", "),
+		tableName,
+	)
+
+	return query + conditionQuery(conditions)
+}
+
+// conditionQuery returns the WHERE clause selecting the rows matching all conditions.
+// It is empty if no conditions are given.
+func conditionQuery(conditions []string) string {
+	if len(conditions) < 1 {
+		return ""
+	}
+
+	wrappedConditions := make([]string, 0, len(conditions))
+	for _, condition := range conditions {
+		wrappedConditions = append(wrappedConditions, fmt.Sprintf("(%s)", condition))
+	}
+
+	return fmt.Sprintf(createViewConditionTemplate, strings.Join(wrappedConditions, " AND "))
+}
+
+const createUniqueIndexQueryTemplate string = "CREATE UNIQUE INDEX %s ON %s (%s)"
+
+// CreateUniqueIndexQuery returns the query for creating a unique index on the given columns
+// of the materialized view. The index is created in the schema of the view.
+// The index, view and column names are expected to be quoted through QuoteIdentifier already.
+func (qb *QueryBuilder) CreateUniqueIndexQuery(indexName string, viewName string, columns []string) string {
+	return fmt.Sprintf(createUniqueIndexQueryTemplate, indexName, viewName, strings.Join(columns, ", "))
+}
+
+const refreshMaterializedViewQueryTemplate string = "REFRESH MATERIALIZED VIEW %s"
+
+const refreshMaterializedViewConcurrentlyQueryTemplate string = "REFRESH MATERIALIZED VIEW CONCURRENTLY %s"
+
+// RefreshMaterializedViewQuery returns the query for refreshing the materialized view for which the name is given.
+// Refreshing concurrently requires a unique index on the view.
+// The view name is expected to be quoted through QuoteIdentifier already.
+func (qb *QueryBuilder) RefreshMaterializedViewQuery(viewName string, concurrently bool) string {
+	if concurrently {
+		return fmt.Sprintf(refreshMaterializedViewConcurrentlyQueryTemplate, viewName)
+	}
+
+	return fmt.Sprintf(refreshMaterializedViewQueryTemplate, viewName)
+}
+
+// viewOptions returns the options of the views in the order they are rendered in.
+func (qb *QueryBuilder) viewOptions() []string {
+	options := make([]string, 0, 2)
+
+	if qb.securityBarrier {
+		options = append(options, "security_barrier")
+	}
+
+	if qb.securityInvoker {
+		options = append(options, "security_invoker")
+	}
+
+	return options
+}
+
+// Minimum values of server_version_num supporting the view options.
+const (
+	securityBarrierMinimumVersion = 90200
+	securityInvokerMinimumVersion = 150000
+)
+
+const serverVersionQuery string = "SHOW server_version_num"
+
+// ServerVersionQuery returns the query for selecting the version of the server as number,
+// e.g. 150004 for PostgreSQL 15.4.
+// If no view options depending on the server version are configured, it returns an empty query,
+// as the version does not have to be checked.
+func (qb *QueryBuilder) ServerVersionQuery() string {
+	if !qb.securityBarrier && !qb.securityInvoker {
+		return ""
+	}
+
+	return serverVersionQuery
+}
+
+// CheckServerVersion returns an error if the server does not support the configured view options.
+// The version is expected in the format returned by ServerVersionQuery.
+func (qb *QueryBuilder) CheckServerVersion(version string) error {
+	versionNumber, err := strconv.Atoi(strings.TrimSpace(version))
+	if err != nil {
+		return fmt.Errorf("Failed to parse server version '%s': %+v", version, err)
+	}
+
+	if qb.securityBarrier && versionNumber < securityBarrierMinimumVersion {
+		return fmt.Errorf(
+			"Security barrier views require PostgreSQL 9.2 or later, but the server runs version %s",
+			formatServerVersion(versionNumber),
+		)
+	}
+
+	if qb.securityInvoker && versionNumber < securityInvokerMinimumVersion {
+		return fmt.Errorf(
+			"Security invoker views require PostgreSQL 15 or later, but the server runs version %s",
+			formatServerVersion(versionNumber),
+		)
+	}
+
+	return nil
+}
+
+// formatServerVersion formats the version number in the way PostgreSQL displays it.
+// Starting with PostgreSQL 10, the version consists of the major and minor version only.
+func formatServerVersion(versionNumber int) string {
+	if versionNumber >= 100000 {
+		return fmt.Sprintf("%d.%d", versionNumber/10000, versionNumber%10000)
+	}
+
+	return fmt.Sprintf("%d.%d.%d", versionNumber/10000, versionNumber/100%100, versionNumber%100)
+}
+
+const castQueryTemplate string = "(%s)::%s"
+
+// CastQuery returns the partial query casting the expression to the given type.
+func (qb *QueryBuilder) CastQuery(expression string, typeName string) string {
+	return fmt.Sprintf(castQueryTemplate, expression, typeName)
+}
+
+// NoRowsCondition returns the condition used for views that must not contain any rows.
+func (qb *QueryBuilder) NoRowsCondition() string {
+	return "false"
+}
+
+const unmaskQueryTemplate string = "CASE WHEN pg_has_role(current_user, %s, 'member') THEN %s ELSE %s END"
+
+// UnmaskQuery returns the partial query returning the original value to members of the given role
+// and the anonymized value to everyone else. The role is quoted with QuoteLiteral.
+func (qb *QueryBuilder) UnmaskQuery(role string, original string, anonymized string) string {
+	return fmt.Sprintf(unmaskQueryTemplate, QuoteLiteral(role), original, anonymized)
+}
+
+// QuoteIdentifier quotes the given identifier with double quotes if required.
+// See the package level QuoteIdentifier function for details.
+func (qb *QueryBuilder) QuoteIdentifier(identifier string) string {
+	return QuoteIdentifier(identifier)
+}
+
+// QuoteLiteral quotes the given value as PostgreSQL string literal.
+// See the package level QuoteLiteral function for details.
+func (qb *QueryBuilder) QuoteLiteral(literal string) string {
+	return QuoteLiteral(literal)
+}
+
+// QueryBuilderOption is a function type following the option function pattern.
+// It can be used to define methods of configuring the QueryBuilder object.
+type QueryBuilderOption func(*QueryBuilder)
+
+// WithSecurityBarrier is a QueryBuilderOption, which creates the views with the security_barrier option.
+// It prevents functions and operators in the conditions of queries on the views from observing
+// rows excluded by row filters before the filters are applied.
+// It requires PostgreSQL 9.2 or later.
+func WithSecurityBarrier() QueryBuilderOption {
+	return func(qb *QueryBuilder) {
+		qb.securityBarrier = true
+	}
+}
+
+// WithSecurityInvoker is a QueryBuilderOption, which creates the views with the security_invoker option.
+// The privileges on the tables are then checked for the user querying the views
+// instead of the owner of the views, so SELECT on the tables must not be revoked
+// from the users of the views.
+// It requires PostgreSQL 15 or later.
+func WithSecurityInvoker() QueryBuilderOption {
+	return func(qb *QueryBuilder) {
+		qb.securityInvoker = true
+	}
 }
@@ -1,11 +1,33 @@
 package postgres
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/viafintech/gotidus"
 	"github.com/viafintech/gotidus/testutils"
 )
 
+func TestQueryBuilderImplementsOptionalInterfaces(t *testing.T) {
+	var queryBuilder gotidus.QueryBuilder = NewQueryBuilder()
+
+	implementations := map[string]bool{}
+
+	_, implementations["SyncQueryBuilder"] = queryBuilder.(gotidus.SyncQueryBuilder)
+	_, implementations["CastQueryBuilder"] = queryBuilder.(gotidus.CastQueryBuilder)
+	_, implementations["FilteredViewQueryBuilder"] = queryBuilder.(gotidus.FilteredViewQueryBuilder)
+	_, implementations["UnmaskQueryBuilder"] = queryBuilder.(gotidus.UnmaskQueryBuilder)
+	_, implementations["GrantQueryBuilder"] = queryBuilder.(gotidus.GrantQueryBuilder)
+	_, implementations["ServerVersionChecker"] = queryBuilder.(gotidus.ServerVersionChecker)
+	_, implementations["MaterializedViewQueryBuilder"] = queryBuilder.(gotidus.MaterializedViewQueryBuilder)
+
+	for name, implemented := range implementations {
+		if !implemented {
+			t.Errorf("QueryBuilder does not implement gotidus.%s", name)
+		}
+	}
+}
+
 func TestQueryBuilderQueries(t *testing.T) {
 	queryBuilder := NewQueryBuilder()
 
@@ -19,9 +41,18 @@ func TestQueryBuilderQueries(t *testing.T) {
 			query: queryBuilder.ListViewsQuery(),
 			expectedQuery: `
   SELECT
-    viewname
+    viewname,
+    false AS materialized
   FROM pg_catalog.pg_views
-  WHERE viewname ILIKE '%' || $1
+  WHERE schemaname = COALESCE(NULLIF($1, ''), CURRENT_SCHEMA)
+    AND viewname ILIKE '%' || $2
+  UNION ALL
+  SELECT
+    matviewname,
+    true
+  FROM pg_catalog.pg_matviews
+  WHERE schemaname = COALESCE(NULLIF($1, ''), CURRENT_SCHEMA)
+    AND matviewname ILIKE '%' || $2
   ORDER BY viewname ASC`,
 		},
 		{
@@ -29,13 +60,74 @@ func TestQueryBuilderQueries(t *testing.T) {
 			query:         queryBuilder.DropViewQuery("transactions_anonymized"),
 			expectedQuery: "DROP VIEW IF EXISTS transactions_anonymized",
 		},
+		{
+			title:         "drop materialized view query",
+			query:         queryBuilder.DropMaterializedViewQuery("transactions_anonymized"),
+			expectedQuery: "DROP MATERIALIZED VIEW IF EXISTS transactions_anonymized",
+		},
+		{
+			title: "list view fingerprints query",
+			query: queryBuilder.ListViewFingerprintsQuery(),
+			expectedQuery: `
+  SELECT
+    c.relname,
+    obj_description(c.oid, 'pg_class'),
+    c.relkind = 'm' AS materialized
+  FROM pg_catalog.pg_class c
+  JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+  WHERE c.relkind IN ('v', 'm')
+    AND n.nspname = COALESCE(NULLIF($1, ''), CURRENT_SCHEMA)
+    AND c.relname ILIKE '%' || $2
+  ORDER BY c.relname ASC`,
+		},
+		{
+			title:         "comment view query",
+			query:         queryBuilder.CommentViewQuery("transactions_anonymized", "gotidus:sha256:abc"),
+			expectedQuery: "COMMENT ON VIEW transactions_anonymized IS 'gotidus:sha256:abc'",
+		},
+		{
+			title: "comment materialized view query",
+			query: queryBuilder.CommentMaterializedViewQuery(
+				"transactions_anonymized",
+				"gotidus:sha256:abc",
+			),
+			expectedQuery: "COMMENT ON MATERIALIZED VIEW transactions_anonymized IS 'gotidus:sha256:abc'",
+		},
+		{
+			title: "list view grantees query",
+			query: queryBuilder.ListViewGranteesQuery(),
+			expectedQuery: `
+  SELECT DISTINCT
+    c.relname,
+    COALESCE(r.rolname, 'PUBLIC') AS rolname
+  FROM pg_catalog.pg_class c
+  JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+  CROSS JOIN LATERAL aclexplode(c.relacl) a
+  LEFT JOIN pg_catalog.pg_roles r ON r.oid = a.grantee
+  WHERE c.relkind IN ('v', 'm')
+    AND n.nspname = COALESCE(NULLIF($1, ''), CURRENT_SCHEMA)
+    AND c.relname ILIKE '%' || $2
+    AND a.privilege_type = 'SELECT'
+    AND a.grantee <> c.relowner
+  ORDER BY c.relname ASC, rolname ASC`,
+		},
+		{
+			title:         "grant select query",
+			query:         queryBuilder.GrantSelectQuery("transactions_anonymized", []string{"analytics", `"Support"`}),
+			expectedQuery: `GRANT SELECT ON transactions_anonymized TO analytics, "Support"`,
+		},
+		{
+			title:         "revoke select query",
+			query:         queryBuilder.RevokeSelectQuery("transactions", []string{"analytics"}),
+			expectedQuery: "REVOKE SELECT ON transactions FROM analytics",
+		},
 		{
 			title: "list tables query",
 			query: queryBuilder.ListTablesQuery(),
 			expectedQuery: `
-  SELECT tablename
+  SELECT schemaname, tablename
   FROM pg_catalog.pg_tables
-  WHERE schemaname = CURRENT_SCHEMA
+  WHERE schemaname = COALESCE(NULLIF($1, ''), CURRENT_SCHEMA)
   ORDER BY tablename ASC`,
 		},
 		{
@@ -43,10 +135,25 @@ func TestQueryBuilderQueries(t *testing.T) {
 			query: queryBuilder.ListColumnsQuery(),
 			expectedQuery: `
   SELECT
-    column_name
-  FROM information_schema.columns
-  WHERE table_name = $1
-  ORDER BY ordinal_position ASC`,
+    c.column_name,
+    c.data_type,
+    c.ordinal_position,
+    c.is_nullable = 'YES' AS is_nullable,
+    c.column_default,
+    c.character_maximum_length,
+    c.udt_name,
+    pg_catalog.format_type(a.atttypid, a.atttypmod) AS formatted_type,
+    t.typcategory::text AS type_category,
+    c.domain_name
+  FROM information_schema.columns c
+  LEFT JOIN pg_catalog.pg_attribute a
+    ON a.attrelid = (quote_ident(c.table_schema) || '.' || quote_ident(c.table_name))::regclass
+    AND a.attname = c.column_name
+  LEFT JOIN pg_catalog.pg_type t
+    ON t.oid = a.atttypid
+  WHERE c.table_schema = $1
+    AND c.table_name = $2
+  ORDER BY c.ordinal_position ASC`,
 		},
 		{
 			title: "create view query",
@@ -60,6 +167,66 @@ func TestQueryBuilderQueries(t *testing.T) {
     SELECT id AS id, amount AS amount
     FROM transactions`,
 		},
+		{
+			title: "create filtered view query",
+			query: queryBuilder.CreateFilteredViewQuery(
+				"transactions_anonymized",
+				"transactions",
+				[]string{"id AS id", "amount AS amount"},
+				"deleted_at IS NULL",
+				"a = 1 OR b = 2",
+			),
+			expectedQuery: `
+  CREATE OR REPLACE VIEW transactions_anonymized AS
+    SELECT id AS id, amount AS amount
+    FROM transactions
+    WHERE (deleted_at IS NULL) AND (a = 1 OR b = 2)`,
+		},
+		{
+			title: "create materialized view query",
+			query: queryBuilder.CreateMaterializedViewQuery(
+				"transactions_anonymized",
+				"transactions",
+				[]string{"id AS id", "amount AS amount"},
+			),
+			expectedQuery: `
+  CREATE MATERIALIZED VIEW transactions_anonymized AS
+    SELECT id AS id, amount AS amount
+    FROM transactions`,
+		},
+		{
+			title: "create materialized view query with conditions",
+			query: queryBuilder.CreateMaterializedViewQuery(
+				"transactions_anonymized",
+				"transactions",
+				[]string{"id AS id"},
+				"deleted_at IS NULL",
+			),
+			expectedQuery: `
+  CREATE MATERIALIZED VIEW transactions_anonymized AS
+    SELECT id AS id
+    FROM transactions
+    WHERE (deleted_at IS NULL)`,
+		},
+		{
+			title: "create unique index query",
+			query: queryBuilder.CreateUniqueIndexQuery(
+				"transactions_anonymized_key",
+				"billing.transactions_anonymized",
+				[]string{"tenant_id", "id"},
+			),
+			expectedQuery: "CREATE UNIQUE INDEX transactions_anonymized_key ON billing.transactions_anonymized (tenant_id, id)",
+		},
+		{
+			title:         "refresh materialized view query",
+			query:         queryBuilder.RefreshMaterializedViewQuery("transactions_anonymized", false),
+			expectedQuery: "REFRESH MATERIALIZED VIEW transactions_anonymized",
+		},
+		{
+			title:         "refresh materialized view query concurrently",
+			query:         queryBuilder.RefreshMaterializedViewQuery("transactions_anonymized", true),
+			expectedQuery: "REFRESH MATERIALIZED VIEW CONCURRENTLY transactions_anonymized",
+		},
 	}
 
 	for _, c := range cases {
@@ -68,3 +235,160 @@ func TestQueryBuilderQueries(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryBuilderQuoteIdentifier(t *testing.T) {
+	queryBuilder := NewQueryBuilder()
+
+	cases := []struct {
+		identifier         string
+		expectedIdentifier string
+	}{
+		{identifier: "transactions", expectedIdentifier: "transactions"},
+		{identifier: "Transactions", expectedIdentifier: `"Transactions"`},
+		{identifier: "order", expectedIdentifier: `"order"`},
+		{identifier: "first name", expectedIdentifier: `"first name"`},
+		{identifier: `some"name`, expectedIdentifier: `"some""name"`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.identifier, func(t *testing.T) {
+			testutils.CompareStrings(queryBuilder.QuoteIdentifier(c.identifier), c.expectedIdentifier, t)
+		})
+	}
+}
+
+func TestQueryBuilderCastQuery(t *testing.T) {
+	testutils.CompareStrings(
+		NewQueryBuilder().CastQuery("NULL::text", "character varying(64)"),
+		"(NULL::text)::character varying(64)",
+		t,
+	)
+}
+
+func TestQueryBuilderNoRowsCondition(t *testing.T) {
+	testutils.CompareStrings(NewQueryBuilder().NoRowsCondition(), "false", t)
+}
+
+func TestQueryBuilderUnmaskQuery(t *testing.T) {
+	testutils.CompareStrings(
+		NewQueryBuilder().UnmaskQuery("support", "users.email", "NULL::text"),
+		"CASE WHEN pg_has_role(current_user, 'support', 'member') THEN users.email ELSE NULL::text END",
+		t,
+	)
+}
+
+func TestQueryBuilderCreateViewQueryWithOptions(t *testing.T) {
+	cases := []struct {
+		title   string
+		options []QueryBuilderOption
+
+		expectedQuery string
+	}{
+		{
+			title:   "with security barrier",
+			options: []QueryBuilderOption{WithSecurityBarrier()},
+
+			expectedQuery: `
+  CREATE OR REPLACE VIEW transactions_anonymized WITH (security_barrier) AS
+    SELECT id AS id
+    FROM transactions
+    WHERE (deleted_at IS NULL)`,
+		},
+		{
+			title:   "with security barrier and invoker",
+			options: []QueryBuilderOption{WithSecurityInvoker(), WithSecurityBarrier()},
+
+			expectedQuery: `
+  CREATE OR REPLACE VIEW transactions_anonymized WITH (security_barrier, security_invoker) AS
+    SELECT id AS id
+    FROM transactions
+    WHERE (deleted_at IS NULL)`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			testutils.CompareStrings(
+				NewQueryBuilder(c.options...).CreateFilteredViewQuery(
+					"transactions_anonymized",
+					"transactions",
+					[]string{"id AS id"},
+					"deleted_at IS NULL",
+				),
+				c.expectedQuery,
+				t,
+			)
+		})
+	}
+}
+
+func TestQueryBuilderCheckServerVersion(t *testing.T) {
+	cases := []struct {
+		title   string
+		options []QueryBuilderOption
+		version string
+
+		expectedQuery string
+		expectedError error
+	}{
+		{
+			title:   "without view options",
+			version: "90100",
+
+			expectedQuery: "",
+		},
+		{
+			title:   "security barrier supported",
+			options: []QueryBuilderOption{WithSecurityBarrier()},
+			version: "90200",
+
+			expectedQuery: "SHOW server_version_num",
+		},
+		{
+			title:   "security barrier not supported",
+			options: []QueryBuilderOption{WithSecurityBarrier()},
+			version: "90124",
+
+			expectedQuery: "SHOW server_version_num",
+			expectedError: errors.New(
+				"Security barrier views require PostgreSQL 9.2 or later, but the server runs version 9.1.24",
+			),
+		},
+		{
+			title:   "security invoker supported",
+			options: []QueryBuilderOption{WithSecurityInvoker()},
+			version: "150004",
+
+			expectedQuery: "SHOW server_version_num",
+		},
+		{
+			title:   "security invoker not supported",
+			options: []QueryBuilderOption{WithSecurityBarrier(), WithSecurityInvoker()},
+			version: "140009",
+
+			expectedQuery: "SHOW server_version_num",
+			expectedError: errors.New(
+				"Security invoker views require PostgreSQL 15 or later, but the server runs version 14.9",
+			),
+		},
+		{
+			title:   "invalid version",
+			options: []QueryBuilderOption{WithSecurityInvoker()},
+			version: "15.4",
+
+			expectedQuery: "SHOW server_version_num",
+			expectedError: errors.New(
+				"Failed to parse server version '15.4': strconv.Atoi: parsing \"15.4\": invalid syntax",
+			),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			queryBuilder := NewQueryBuilder(c.options...)
+
+			testutils.CompareStrings(queryBuilder.ServerVersionQuery(), c.expectedQuery, t)
+			testutils.CompareStructs(queryBuilder.CheckServerVersion(c.version), c.expectedError, t)
+		})
+	}
+}
@@ -2,8 +2,6 @@ package postgres
 
 import (
 	"fmt"
-
-	"github.com/viafintech/gotidus"
 )
 
 // RegexReplaceAnonymizer is a gotidus.Anonymizer interface implementation which allows
@@ -26,9 +24,9 @@ func NewRegexReplaceAnonymizer(pattern, replacement string) *RegexReplaceAnonymi
 // It uses the configured pattern and replacement and applies it to the given column.
 func (a *RegexReplaceAnonymizer) Build(tableName, columnName string) string {
 	return fmt.Sprintf(
-		`REGEXP_REPLACE(%s, '%s', '%s')`,
-		gotidus.FullColumnName(tableName, columnName),
-		a.pattern,
-		a.replacement,
+		`REGEXP_REPLACE(%s, %s, %s)`,
+		FullColumnName(tableName, columnName),
+		QuoteLiteral(a.pattern),
+		QuoteLiteral(a.replacement),
 	)
 }
@@ -3,8 +3,6 @@ package postgres
 import (
 	"fmt"
 	"strings"
-
-	"github.com/viafintech/gotidus"
 )
 
 // RemoveJSONKeysAnonymizer is a gotidus.Anonymizer interface implementation which allows
@@ -26,7 +24,7 @@ func NewRemoveJSONKeysAnonymizer(keys []string) *RemoveJSONKeysAnonymizer {
 func (a *RemoveJSONKeysAnonymizer) Build(tableName, columnName string) string {
 	removedKeys := make([]string, len(a.keys))
 	for i, key := range a.keys {
-		removedKeys[i] = fmt.Sprintf("key <> '%s'", key)
+		removedKeys[i] = fmt.Sprintf("key <> %s", QuoteLiteral(key))
 	}
 
 	removedKeysString := strings.Join(removedKeys, " AND ")
@@ -37,7 +35,7 @@ func (a *RemoveJSONKeysAnonymizer) Build(tableName, columnName string) string {
         concat('{', string_agg(to_json("key") || ':' || "value", ','), '}')::JSON
       FROM json_each(%[1]s::JSON) WHERE %[2]s
     )`,
-		gotidus.FullColumnName(tableName, columnName),
+		FullColumnName(tableName, columnName),
 		removedKeysString,
 	)
 }
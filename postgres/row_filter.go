@@ -0,0 +1,33 @@
+package postgres
+
+// ConditionRowFilter is a gotidus.RowFilter interface implementation
+// which compares a column of the table with a value.
+// It follows the same model as the AnonymizationCondition of the ConditionAnonymizer.
+type ConditionRowFilter struct {
+	column     string
+	comparator string
+	value      string
+	dataType   string
+}
+
+// NewConditionRowFilter initializes a new ConditionRowFilter object.
+// The column and the value are cast to the given data type for the comparison.
+func NewConditionRowFilter(
+	column string,
+	comparator string,
+	value string,
+	dataType string,
+) *ConditionRowFilter {
+	return &ConditionRowFilter{
+		column:     column,
+		comparator: comparator,
+		value:      value,
+		dataType:   dataType,
+	}
+}
+
+// Build returns the comparison as partial query.
+// The value is quoted with QuoteLiteral.
+func (f *ConditionRowFilter) Build(tableName string) string {
+	return buildComparison(tableName, f.column, f.comparator, f.value, f.dataType)
+}
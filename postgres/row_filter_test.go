@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/viafintech/gotidus/testutils"
+)
+
+func TestConditionRowFilterBuild(t *testing.T) {
+	cases := []struct {
+		title      string
+		column     string
+		comparator string
+		value      string
+		dataType   string
+
+		expectedString string
+	}{
+		{
+			title:      "compare with a value",
+			column:     "email",
+			comparator: "NOT LIKE",
+			value:      "%@example.com",
+			dataType:   "TEXT",
+
+			expectedString: `((accounts.email)::TEXT) NOT LIKE '%@example.com'::TEXT`,
+		},
+		{
+			title:      "value with quotes",
+			column:     "created_at",
+			comparator: ">=",
+			value:      "2020-01-01' OR '1'='1",
+			dataType:   "DATE",
+
+			expectedString: `((accounts.created_at)::DATE) >= '2020-01-01'' OR ''1''=''1'::DATE`,
+		},
+		{
+			title:      "without data type",
+			column:     "state",
+			comparator: "<>",
+			value:      "deleted",
+
+			expectedString: `(accounts.state) <> 'deleted'`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			filter := NewConditionRowFilter(c.column, c.comparator, c.value, c.dataType)
+
+			testutils.CompareStrings(filter.Build("accounts"), c.expectedString, t)
+		})
+	}
+}
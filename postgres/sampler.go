@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"fmt"
+	"math"
+)
+
+// sampleBuckets is the number of buckets rows are distributed to by the HashSampler,
+// which allows percentages with a precision of two decimal places.
+const sampleBuckets = 10000
+
+// HashSampler is a gotidus.RowFilter interface implementation
+// which selects a deterministic sample of rows based on the hash of a key column.
+// As rows with the same key value are always either part of the sample or not,
+// sampling several tables on the same key, e.g. customer_id, keeps related rows together.
+type HashSampler struct {
+	column  string
+	percent float64
+}
+
+// NewHashSampler initializes a new HashSampler object.
+// The percentage of rows to select has to be between 0 and 100,
+// otherwise the Generator fails to build the view.
+func NewHashSampler(column string, percent float64) *HashSampler {
+	return &HashSampler{
+		column:  column,
+		percent: percent,
+	}
+}
+
+// Build returns the partial query selecting the rows of the sample.
+// The key is hashed with MD5, which is stable across runs and PostgreSQL versions,
+// and the first 32 bits of the hash are distributed to 10000 buckets.
+func (s *HashSampler) Build(tableName string) string {
+	return fmt.Sprintf(
+		"(('x' || SUBSTR(MD5((%s)::text), 1, 8))::bit(32)::bigint %% %d) < %d",
+		FullColumnName(tableName, s.column),
+		sampleBuckets,
+		int(math.Round(s.percent*sampleBuckets/100)),
+	)
+}
+
+// Validate returns an error if the percentage is not between 0 and 100.
+func (s *HashSampler) Validate() error {
+	if math.IsNaN(s.percent) || s.percent < 0 || s.percent > 100 {
+		return fmt.Errorf("Percentage %v of HashSampler is not between 0 and 100", s.percent)
+	}
+
+	return nil
+}
+
+// ModuloSampler is a gotidus.RowFilter interface implementation
+// which selects the rows whose integer key column has the given remainder
+// when divided by the modulus, e.g. every tenth row by id.
+type ModuloSampler struct {
+	column    string
+	modulus   int
+	remainder int
+}
+
+// NewModuloSampler initializes a new ModuloSampler object.
+// The modulus has to be positive and the remainder between 0 and the modulus (exclusive),
+// otherwise the Generator fails to build the view.
+func NewModuloSampler(column string, modulus int, remainder int) *ModuloSampler {
+	return &ModuloSampler{
+		column:    column,
+		modulus:   modulus,
+		remainder: remainder,
+	}
+}
+
+// Validate returns an error if the modulus is not positive,
+// as a modulus of 0 fails with a division by zero when querying the view,
+// or if the remainder is out of range, as the sample would always be empty.
+func (s *ModuloSampler) Validate() error {
+	if s.modulus < 1 {
+		return fmt.Errorf("Modulus %d of ModuloSampler is not positive", s.modulus)
+	}
+
+	if s.remainder < 0 || s.remainder >= s.modulus {
+		return fmt.Errorf(
+			"Remainder %d of ModuloSampler is not between 0 and modulus %d",
+			s.remainder,
+			s.modulus,
+		)
+	}
+
+	return nil
+}
+
+// Build returns the partial query selecting the rows of the sample.
+// As MOD keeps the sign of the key, the remainder of negative keys is shifted
+// into the range of the modulus, so that they are sampled as well.
+func (s *ModuloSampler) Build(tableName string) string {
+	return fmt.Sprintf(
+		"MOD(MOD((%s)::bigint, %d) + %d, %d) = %d",
+		FullColumnName(tableName, s.column),
+		s.modulus,
+		s.modulus,
+		s.modulus,
+		s.remainder,
+	)
+}
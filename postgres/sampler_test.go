@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/viafintech/gotidus/testutils"
+)
+
+func TestHashSamplerBuild(t *testing.T) {
+	cases := []struct {
+		title   string
+		column  string
+		percent float64
+
+		expectedString string
+	}{
+		{
+			title:   "whole percentage",
+			column:  "customer_id",
+			percent: 10,
+
+			expectedString: `(('x' || SUBSTR(MD5((transactions.customer_id)::text), 1, 8))::bit(32)::bigint % 10000) < 1000`,
+		},
+		{
+			title:   "fractional percentage",
+			column:  "customer_id",
+			percent: 0.25,
+
+			expectedString: `(('x' || SUBSTR(MD5((transactions.customer_id)::text), 1, 8))::bit(32)::bigint % 10000) < 25`,
+		},
+		{
+			title:   "quoted column",
+			column:  "User",
+			percent: 50,
+
+			expectedString: `(('x' || SUBSTR(MD5((transactions."User")::text), 1, 8))::bit(32)::bigint % 10000) < 5000`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			sampler := NewHashSampler(c.column, c.percent)
+
+			testutils.CompareStrings(sampler.Build("transactions"), c.expectedString, t)
+		})
+	}
+}
+
+func TestModuloSamplerBuild(t *testing.T) {
+	sampler := NewModuloSampler("id", 10, 3)
+
+	testutils.CompareStrings(
+		sampler.Build("transactions"),
+		"MOD(MOD((transactions.id)::bigint, 10) + 10, 10) = 3",
+		t,
+	)
+}
+
+func TestHashSamplerValidate(t *testing.T) {
+	cases := []struct {
+		title   string
+		percent float64
+
+		expectedError error
+	}{
+		{
+			title:   "valid percentage",
+			percent: 100,
+		},
+		{
+			title:   "negative percentage",
+			percent: -1,
+
+			expectedError: errors.New("Percentage -1 of HashSampler is not between 0 and 100"),
+		},
+		{
+			title:   "percentage above 100",
+			percent: 250,
+
+			expectedError: errors.New("Percentage 250 of HashSampler is not between 0 and 100"),
+		},
+		{
+			title:   "no percentage",
+			percent: math.NaN(),
+
+			expectedError: errors.New("Percentage NaN of HashSampler is not between 0 and 100"),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			sampler := NewHashSampler("customer_id", c.percent)
+
+			testutils.CompareStructs(sampler.Validate(), c.expectedError, t)
+		})
+	}
+}
+
+func TestModuloSamplerValidate(t *testing.T) {
+	testutils.CompareStructs(NewModuloSampler("id", 10, 3).Validate(), nil, t)
+
+	testutils.CompareStructs(
+		NewModuloSampler("id", 0, 0).Validate(),
+		errors.New("Modulus 0 of ModuloSampler is not positive"),
+		t,
+	)
+
+	testutils.CompareStructs(
+		NewModuloSampler("id", 10, 10).Validate(),
+		errors.New("Remainder 10 of ModuloSampler is not between 0 and modulus 10"),
+		t,
+	)
+
+	testutils.CompareStructs(
+		NewModuloSampler("id", 10, -3).Validate(),
+		errors.New("Remainder -3 of ModuloSampler is not between 0 and modulus 10"),
+		t,
+	)
+}
@@ -16,12 +16,11 @@ func NewSHA256Anonymizer(length int) *SHA256Anonymizer {
   }
 }
 
-// Build returns 'NULL::unknown' as partial query.
+// Build returns the SHA256 value of the column value cast to text as partial query.
 func (a *SHA256Anonymizer) Build(tableName, columnName string) string {
   return fmt.Sprintf(
-    "SUBSTRING(ENCODE(DIGEST('%s.%s', 'sha256'), 'HEX'), 0, %d)",
-    tableName,
-    columnName,
+    "SUBSTRING(ENCODE(DIGEST(%s::text, 'sha256'), 'HEX'), 0, %d)",
+    FullColumnName(tableName, columnName),
     // +1 as substring is excluding the last character
     // and passing 10 would only result in 9 characters
     a.length+1,
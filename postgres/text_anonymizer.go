@@ -5,8 +5,6 @@ import (
 	"math/rand"
 	"strings"
 	"time"
-
-	"github.com/viafintech/gotidus"
 )
 
 // TextAnonymizer is a gotidus.Anonymizer interface implementation.
@@ -34,10 +32,10 @@ func (a *TextAnonymizer) mapping() string {
 // the general structure.
 func (a *TextAnonymizer) Build(tableName, columnName string) string {
 	return fmt.Sprintf(
-		`translate(%s::TEXT, '%s'::TEXT, '%s'::TEXT)`,
-		gotidus.FullColumnName(tableName, columnName),
-		a.base(),
-		a.mapping(),
+		`translate(%s::TEXT, %s::TEXT, %s::TEXT)`,
+		FullColumnName(tableName, columnName),
+		QuoteLiteral(a.base()),
+		QuoteLiteral(a.mapping()),
 	)
 }
 
@@ -0,0 +1,63 @@
+package gotidus
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Querier is the interface for the database handles the Generator operates on.
+// It is satisfied by *sql.DB, *sql.Tx and *sql.Conn.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// TxBeginner is the interface for database handles which are able to start a transaction.
+// It is satisfied by *sql.DB and *sql.Conn.
+type TxBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// inTransaction runs txFunc within a new transaction on the given Querier.
+// The transaction is committed if txFunc succeeds and rolled back otherwise.
+// If the Querier is not able to start a transaction, e.g. because it is a *sql.Tx already,
+// txFunc is called with the Querier directly and committing or rolling back
+// is left to the caller.
+func inTransaction(ctx context.Context, q Querier, txFunc func(Querier) error) error {
+	beginner, ok := q.(TxBeginner)
+	if !ok {
+		return txFunc(q)
+	}
+
+	tx, err := beginner.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to begin transaction: %+v", err)
+	}
+
+	if err := txFunc(tx); err != nil {
+		// A cancelled context already causes the transaction to be rolled back.
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			return fmt.Errorf("%+v (rollback failed: %+v)", err, rollbackErr)
+		}
+
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("Failed to commit transaction: %+v", err)
+	}
+
+	return nil
+}
+
+// closeRows closes the rows and returns any error that occurred during iteration.
+func closeRows(rows *sql.Rows) error {
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return err
+	}
+
+	return rows.Close()
+}
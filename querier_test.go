@@ -0,0 +1,105 @@
+package gotidus
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/viafintech/gotidus/testutils"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var (
+	_ Querier = (*sql.DB)(nil)
+	_ Querier = (*sql.Tx)(nil)
+	_ Querier = (*sql.Conn)(nil)
+
+	_ TxBeginner = (*sql.DB)(nil)
+	_ TxBeginner = (*sql.Conn)(nil)
+)
+
+func TestInTransaction(t *testing.T) {
+	ctx := context.Background()
+
+	cases := []struct {
+		title         string
+		buildQuerier  func(*sql.DB) (Querier, error)
+		txFunc        func(Querier) error
+		setupMock     func(sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			title: "database commits on success",
+			buildQuerier: func(db *sql.DB) (Querier, error) {
+				return db, nil
+			},
+			txFunc: func(q Querier) error { return nil },
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectCommit()
+			},
+		},
+		{
+			title: "connection rolls back on failure",
+			buildQuerier: func(db *sql.DB) (Querier, error) {
+				return db.Conn(ctx)
+			},
+			txFunc: func(q Querier) error { return errors.New("simulated failure") },
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+			},
+			expectedError: errors.New("simulated failure"),
+		},
+		{
+			title: "commit fails",
+			buildQuerier: func(db *sql.DB) (Querier, error) {
+				return db, nil
+			},
+			txFunc: func(q Querier) error { return nil },
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectCommit().WillReturnError(errors.New("simulated failure"))
+			},
+			expectedError: errors.New("Failed to commit transaction: simulated failure"),
+		},
+		{
+			title: "existing transaction is left to the caller",
+			buildQuerier: func(db *sql.DB) (Querier, error) {
+				return db.Begin()
+			},
+			txFunc: func(q Querier) error { return errors.New("simulated failure") },
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+			},
+			expectedError: errors.New("simulated failure"),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to initialize DB mock")
+			}
+
+			c.setupMock(dbMock)
+
+			q, err := c.buildQuerier(db)
+			if err != nil {
+				t.Fatalf("Failed to initialize querier: %+v", err)
+			}
+
+			testutils.CompareStructs(
+				inTransaction(ctx, q, c.txFunc),
+				c.expectedError,
+				t,
+			)
+
+			if err := dbMock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Did not execute expected queries")
+			}
+		})
+	}
+}
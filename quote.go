@@ -0,0 +1,49 @@
+package gotidus
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IdentifierQuoter is the interface for quoting table, column and type names
+// in the dialect of the database. It is satisfied by every QueryBuilder.
+type IdentifierQuoter interface {
+	QuoteIdentifier(identifier string) string
+}
+
+// LiteralQuoter is the interface for quoting values as string literals
+// in the dialect of the database. It is satisfied by every QueryBuilder.
+type LiteralQuoter interface {
+	QuoteLiteral(literal string) string
+}
+
+// Quoter is the interface for quoting identifiers and string literals
+// in the dialect of the database. It is satisfied by every QueryBuilder.
+type Quoter interface {
+	IdentifierQuoter
+	LiteralQuoter
+}
+
+// QuoteColumnName builds the full name of the column based on the table and column names,
+// quoting both names with the given IdentifierQuoter.
+func QuoteColumnName(quoter IdentifierQuoter, tableName, columnName string) string {
+	return fmt.Sprintf("%s.%s", quoter.QuoteIdentifier(tableName), quoter.QuoteIdentifier(columnName))
+}
+
+// standardQuoter quotes identifiers and values following the SQL standard
+// for anonymizers built without a Quoter, as the dialect is unknown then.
+// The Generator always passes its QueryBuilder instead.
+type standardQuoter struct{}
+
+// QuoteIdentifier quotes the given identifier with double quotes.
+// As the keywords of the dialect are unknown, every identifier is quoted.
+// Double quotes within the identifier are escaped by doubling them.
+func (q standardQuoter) QuoteIdentifier(identifier string) string {
+	return fmt.Sprintf(`"%s"`, strings.ReplaceAll(identifier, `"`, `""`))
+}
+
+// QuoteLiteral quotes the given value with single quotes.
+// Single quotes within the value are escaped by doubling them.
+func (q standardQuoter) QuoteLiteral(literal string) string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(literal, "'", "''"))
+}
@@ -0,0 +1,48 @@
+package gotidus
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/viafintech/gotidus/testutils"
+)
+
+func TestStandardQuoterQuoteIdentifier(t *testing.T) {
+	testutils.CompareStrings(standardQuoter{}.QuoteIdentifier("user"), `"user"`, t)
+	testutils.CompareStrings(standardQuoter{}.QuoteIdentifier(`a"b`), `"a""b"`, t)
+}
+
+func TestQuoteColumnName(t *testing.T) {
+	testutils.CompareStrings(
+		QuoteColumnName(standardQuoter{}, "Invoices", "user"),
+		`"Invoices"."user"`,
+		t,
+	)
+}
+
+func TestStandardQuoterQuoteLiteral(t *testing.T) {
+	testutils.CompareStrings(standardQuoter{}.QuoteLiteral("value"), "'value'", t)
+	testutils.CompareStrings(standardQuoter{}.QuoteLiteral("it's"), "'it''s'", t)
+}
+
+func FuzzStandardQuoterQuoteLiteral(f *testing.F) {
+	for _, seed := range []string{"", "value", "it's", "''", `\'`} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, literal string) {
+		quoted := standardQuoter{}.QuoteLiteral(literal)
+
+		if len(quoted) < 2 || quoted[0] != '\'' || quoted[len(quoted)-1] != '\'' {
+			t.Fatalf("Literal is not enclosed in single quotes: %s", quoted)
+		}
+
+		// Within the literal, single quotes may only appear as escaped pairs.
+		content := quoted[1 : len(quoted)-1]
+		if strings.Contains(strings.ReplaceAll(content, "''", ""), "'") {
+			t.Fatalf("Literal contains an unescaped single quote: %s", quoted)
+		}
+
+		testutils.CompareStrings(strings.ReplaceAll(content, "''", "'"), literal, t)
+	})
+}
@@ -0,0 +1,55 @@
+package gotidus
+
+import (
+	"fmt"
+)
+
+// RowFilter is the interface for predicates restricting the rows exposed by the view of a table.
+// Build returns the predicate as partial query for the given table name.
+type RowFilter interface {
+	Build(tableName string) string
+}
+
+// ValidatingRowFilter is an optional extension of the RowFilter interface
+// for row filters whose configuration can be invalid, e.g. a sampler with a percentage above 100.
+// The Generator calls Validate before building the view and fails if an error is returned.
+type ValidatingRowFilter interface {
+	RowFilter
+	Validate() error
+}
+
+// SQLRowFilter is a RowFilter interface implementation for raw SQL predicates.
+// It allows predicates not covered by other RowFilter implementations, e.g. deleted_at IS NULL.
+type SQLRowFilter struct {
+	predicate string
+}
+
+// NewSQLRowFilter initializes a new SQLRowFilter object.
+// The predicate is used as is and must not contain user provided values.
+func NewSQLRowFilter(predicate string) *SQLRowFilter {
+	return &SQLRowFilter{predicate: predicate}
+}
+
+// Build returns the predicate given on object initialization.
+// The table name is ignored here.
+func (f *SQLRowFilter) Build(tableName string) string {
+	return f.predicate
+}
+
+// buildRowFilters builds the predicates of all row filters for the given table name.
+// An error is returned if a ValidatingRowFilter is invalid.
+func buildRowFilters(filters []RowFilter, tableName string) ([]string, error) {
+	conditions := make([]string, 0, len(filters))
+
+	for _, filter := range filters {
+		if validating, ok := filter.(ValidatingRowFilter); ok {
+			if err := validating.Validate(); err != nil {
+				return nil, fmt.Errorf("Invalid row filter of table '%s': %+v", tableName, err)
+			}
+		}
+
+		conditions = append(conditions, filter.Build(tableName))
+	}
+
+	return conditions, nil
+}
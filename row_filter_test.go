@@ -0,0 +1,115 @@
+package gotidus
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/viafintech/gotidus/testutils"
+)
+
+func TestSQLRowFilterBuild(t *testing.T) {
+	filter := NewSQLRowFilter("deleted_at IS NULL")
+
+	testutils.CompareStrings(filter.Build("foo"), "deleted_at IS NULL", t)
+}
+
+func TestGeneratorPlanSnapshotWithRowFilters(t *testing.T) {
+	snapshot := &Snapshot{
+		Tables: []SnapshotTable{
+			{
+				Schema: "public",
+				Name:   "foo",
+				Columns: []Column{
+					{Name: "id", DataType: "integer", OrdinalPosition: 1},
+				},
+			},
+		},
+	}
+
+	generator := NewGenerator(&mockQueryBuilder{})
+	generator.AddTable(
+		"foo",
+		NewTable().
+			WithRowFilter(NewSQLRowFilter("deleted_at IS NULL")).
+			WithRowFilter(NewSQLRowFilter("NOT internal")),
+	)
+
+	plan, err := generator.PlanSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("Failed to build plan: %+v", err)
+	}
+
+	testutils.CompareStrings(
+		plan.Statements[0].Query,
+		"create_view_query:foo_anonymized;foo;foo.id AS id;deleted_at IS NULL&NOT internal",
+		t,
+	)
+}
+
+// invalidRowFilter is a ValidatingRowFilter which is always invalid.
+type invalidRowFilter struct{}
+
+func (f *invalidRowFilter) Build(tableName string) string {
+	return "invalid"
+}
+
+func (f *invalidRowFilter) Validate() error {
+	return errors.New("simulated failure")
+}
+
+func TestGeneratorPlanSnapshotWithInvalidRowFilter(t *testing.T) {
+	snapshot := &Snapshot{
+		Tables: []SnapshotTable{
+			{Schema: "public", Name: "foo", Columns: []Column{{Name: "id", DataType: "integer"}}},
+		},
+	}
+
+	generator := NewGenerator(&mockQueryBuilder{})
+	generator.AddTable("foo", NewTable().WithSampling(&invalidRowFilter{}))
+
+	_, err := generator.PlanSnapshot(snapshot)
+
+	testutils.CompareStructs(
+		err,
+		errors.New("Invalid row filter of table 'foo': simulated failure"),
+		t,
+	)
+}
+
+func TestGeneratorPlanSnapshotWithStructureOnlyTables(t *testing.T) {
+	snapshot := &Snapshot{
+		Tables: []SnapshotTable{
+			{Schema: "public", Name: "sessions", Columns: []Column{{Name: "token", DataType: "text"}}},
+			{Schema: "public", Name: "audit_log", Columns: []Column{{Name: "id", DataType: "integer"}}},
+			{Schema: "public", Name: "users", Columns: []Column{{Name: "id", DataType: "integer"}}},
+		},
+	}
+
+	generator := NewGenerator(&mockQueryBuilder{}, WithStructureOnlyTables(MatchGlob("audit_*")))
+	generator.AddTable(
+		"sessions",
+		NewTable().
+			StructureOnly().
+			WithRowFilter(NewSQLRowFilter("expires_at > NOW()")),
+	)
+
+	plan, err := generator.PlanSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("Failed to build plan: %+v", err)
+	}
+
+	queries := make([]string, 0)
+	for _, statement := range plan.Statements {
+		queries = append(queries, statement.Query)
+	}
+
+	testutils.CompareStructs(
+		queries,
+		[]string{
+			"create_view_query:sessions_anonymized;sessions;sessions.token AS token;no_rows_condition",
+			"create_view_query:audit_log_anonymized;audit_log;audit_log.id AS id;no_rows_condition",
+			"create_view_query:users_anonymized;users;users.id AS id",
+		},
+		t,
+	)
+}
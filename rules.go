@@ -0,0 +1,330 @@
+package gotidus
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// NameMatcher is the interface for matching the names of tables or columns.
+type NameMatcher interface {
+	Match(name string) bool
+	String() string
+}
+
+// MatchName returns a NameMatcher matching exactly the given name.
+func MatchName(name string) NameMatcher {
+	return exactMatcher(name)
+}
+
+type exactMatcher string
+
+// Match reports whether the name equals the configured name.
+func (m exactMatcher) Match(name string) bool {
+	return string(m) == name
+}
+
+// String returns the configured name.
+func (m exactMatcher) String() string {
+	return string(m)
+}
+
+// MatchGlob returns a NameMatcher matching names against the given glob pattern,
+// e.g. *_email. The pattern syntax is the one of path.Match.
+// It panics if the pattern is malformed.
+func MatchGlob(pattern string) NameMatcher {
+	if _, err := path.Match(pattern, ""); err != nil {
+		panic(fmt.Sprintf("Invalid glob pattern '%s': %+v", pattern, err))
+	}
+
+	return globMatcher(pattern)
+}
+
+type globMatcher string
+
+// Match reports whether the name matches the glob pattern.
+func (m globMatcher) Match(name string) bool {
+	matched, _ := path.Match(string(m), name)
+
+	return matched
+}
+
+// String returns the glob pattern prefixed with glob:.
+func (m globMatcher) String() string {
+	return fmt.Sprintf("glob:%s", string(m))
+}
+
+// MatchRegexp returns a NameMatcher matching names against the given regular expression.
+// The expression is not anchored, so ^ and $ have to be used to match whole names.
+// It panics if the expression cannot be parsed.
+func MatchRegexp(expression string) NameMatcher {
+	return &regexpMatcher{regexp: regexp.MustCompile(expression)}
+}
+
+type regexpMatcher struct {
+	regexp *regexp.Regexp
+}
+
+// Match reports whether the name matches the regular expression.
+func (m *regexpMatcher) Match(name string) bool {
+	return m.regexp.MatchString(name)
+}
+
+// String returns the regular expression prefixed with regexp:.
+func (m *regexpMatcher) String() string {
+	return fmt.Sprintf("regexp:%s", m.regexp.String())
+}
+
+// NewColumnRule initializes a ColumnRule applying the anonymizer to all columns
+// matched by the given NameMatcher across all tables.
+func NewColumnRule(columns NameMatcher, anonymizer Anonymizer) *ColumnRule {
+	return &ColumnRule{
+		columns:    columns,
+		anonymizer: anonymizer,
+	}
+}
+
+// ColumnRule is the type holding an Anonymizer for all columns with matching names.
+type ColumnRule struct {
+	columns    NameMatcher
+	tables     NameMatcher
+	anonymizer Anonymizer
+}
+
+// ForTables restricts the rule to the tables matched by the given NameMatcher.
+// Tables are matched by their name as well as by their schema qualified name <schema>.<table>.
+func (r *ColumnRule) ForTables(tables NameMatcher) *ColumnRule {
+	r.tables = tables
+
+	return r
+}
+
+// String describes the rule through its matchers.
+func (r *ColumnRule) String() string {
+	if r.tables == nil {
+		return fmt.Sprintf("columns %s", r.columns)
+	}
+
+	return fmt.Sprintf("columns %s in tables %s", r.columns, r.tables)
+}
+
+func (r *ColumnRule) match(table SnapshotTable, column Column) bool {
+	if r.tables != nil &&
+		!r.tables.Match(table.Name) &&
+		!r.tables.Match(qualifiedName(table.Schema, table.Name)) {
+		return false
+	}
+
+	return r.columns.Match(column.Name)
+}
+
+// AddColumnRule adds a ColumnRule to the generator.
+// Rules apply to columns without an Anonymizer configured on their Table.
+// If multiple rules match a column, the rule added first is applied.
+func (g *Generator) AddColumnRule(rule *ColumnRule) *Generator {
+	g.columnRules = append(g.columnRules, rule)
+
+	return g
+}
+
+// TypeMatcher is the interface for matching columns by their type.
+type TypeMatcher interface {
+	Match(column Column) bool
+	String() string
+}
+
+// MatchType returns a TypeMatcher matching columns of the given type, e.g. inet or jsonb.
+// The type is compared case-insensitively to the data type and the underlying type name
+// of the column, so both character varying and varchar match varchar columns.
+func MatchType(typeName string) TypeMatcher {
+	return typeNameMatcher(typeName)
+}
+
+type typeNameMatcher string
+
+// Match reports whether the column is of the configured type.
+func (m typeNameMatcher) Match(column Column) bool {
+	return strings.EqualFold(column.DataType, string(m)) ||
+		strings.EqualFold(column.UDTName, string(m))
+}
+
+// String returns the configured type name prefixed with type:.
+func (m typeNameMatcher) String() string {
+	return fmt.Sprintf("type:%s", string(m))
+}
+
+// MatchTypeCategory returns a TypeMatcher matching columns of types in the given category.
+// Categories are identified by a single character as in the typcategory column
+// of the PostgreSQL pg_type catalog, e.g. N for numeric or I for network address types.
+func MatchTypeCategory(category string) TypeMatcher {
+	return typeCategoryMatcher(category)
+}
+
+type typeCategoryMatcher string
+
+// Match reports whether the type of the column is in the configured category.
+func (m typeCategoryMatcher) Match(column Column) bool {
+	return column.TypeCategory == string(m)
+}
+
+// String returns the configured category prefixed with category:.
+func (m typeCategoryMatcher) String() string {
+	return fmt.Sprintf("category:%s", string(m))
+}
+
+// MatchDomain returns a TypeMatcher matching columns based on the domain of the given name.
+func MatchDomain(domainName string) TypeMatcher {
+	return domainMatcher(domainName)
+}
+
+type domainMatcher string
+
+// Match reports whether the column is based on the configured domain.
+func (m domainMatcher) Match(column Column) bool {
+	return column.DomainName == string(m)
+}
+
+// String returns the configured domain name prefixed with domain:.
+func (m domainMatcher) String() string {
+	return fmt.Sprintf("domain:%s", string(m))
+}
+
+// NewTypeRule initializes a TypeRule applying the anonymizer to all columns
+// matched by the given TypeMatcher across all tables.
+func NewTypeRule(types TypeMatcher, anonymizer Anonymizer) *TypeRule {
+	return &TypeRule{
+		types:      types,
+		anonymizer: anonymizer,
+	}
+}
+
+// TypeRule is the type holding an Anonymizer for all columns with matching types.
+type TypeRule struct {
+	types      TypeMatcher
+	anonymizer Anonymizer
+}
+
+// String describes the rule through its matcher.
+func (r *TypeRule) String() string {
+	return fmt.Sprintf("columns of %s", r.types)
+}
+
+// AddTypeRule adds a TypeRule to the generator.
+// Type rules apply to columns without an Anonymizer configured on their Table
+// or through a ColumnRule. If multiple type rules match a column, the rule added first is applied.
+func (g *Generator) AddTypeRule(rule *TypeRule) *Generator {
+	g.typeRules = append(g.typeRules, rule)
+
+	return g
+}
+
+// AnonymizerSource describes where the Anonymizer of a column was configured.
+type AnonymizerSource string
+
+// AnonymizerSource constants in the order of their precedence.
+const (
+	AnonymizerSourceTable      AnonymizerSource = "table"
+	AnonymizerSourceColumnRule AnonymizerSource = "column rule"
+	AnonymizerSourceTypeRule   AnonymizerSource = "type rule"
+	AnonymizerSourceDefault    AnonymizerSource = "default"
+	AnonymizerSourceNone       AnonymizerSource = "none"
+)
+
+// anonymizerResolution is the Anonymizer resolved for a column along with its source.
+type anonymizerResolution struct {
+	anonymizer Anonymizer
+	source     AnonymizerSource
+	rule       string
+}
+
+// resolveAnonymizer resolves the Anonymizer for the column of the given table.
+// An Anonymizer configured on the table takes precedence over the column rules,
+// followed by the type rules and the default anonymizer of the Generator.
+// If none of them apply, the NoopAnonymizer is used.
+func (g *Generator) resolveAnonymizer(snapshotTable SnapshotTable, column Column) anonymizerResolution {
+	table := g.getTable(snapshotTable.Schema, snapshotTable.Name)
+
+	if anonymizer, ok := table.getAnonymizer(column.Name); ok {
+		return anonymizerResolution{anonymizer: anonymizer, source: AnonymizerSourceTable}
+	}
+
+	for _, rule := range g.columnRules {
+		if rule.match(snapshotTable, column) {
+			return anonymizerResolution{
+				anonymizer: rule.anonymizer,
+				source:     AnonymizerSourceColumnRule,
+				rule:       rule.String(),
+			}
+		}
+	}
+
+	for _, rule := range g.typeRules {
+		if rule.types.Match(column) {
+			return anonymizerResolution{
+				anonymizer: rule.anonymizer,
+				source:     AnonymizerSourceTypeRule,
+				rule:       rule.String(),
+			}
+		}
+	}
+
+	if g.defaultAnonymizer != nil {
+		return anonymizerResolution{anonymizer: g.defaultAnonymizer, source: AnonymizerSourceDefault}
+	}
+
+	return anonymizerResolution{anonymizer: NewNoopAnonymizer(), source: AnonymizerSourceNone}
+}
+
+// ColumnExplanation describes which configuration applies to a column.
+// Rule describes the matching rule if the Anonymizer was configured by a rule.
+// Omitted reports whether the column is omitted from the view.
+type ColumnExplanation struct {
+	Table   string
+	Column  string
+	Source  AnonymizerSource
+	Rule    string
+	Omitted bool
+}
+
+// Explain reads the tables and columns from the database and describes
+// for each column where its Anonymizer was configured.
+func (g *Generator) Explain(ctx context.Context, db Querier) ([]ColumnExplanation, error) {
+	snapshot, err := g.Snapshot(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.ExplainSnapshot(snapshot), nil
+}
+
+// ExplainSnapshot is the equivalent of Explain for the tables and columns of the given Snapshot.
+func (g *Generator) ExplainSnapshot(snapshot *Snapshot) []ColumnExplanation {
+	explanations := make([]ColumnExplanation, 0)
+
+	for _, snapshotTable := range snapshot.Tables {
+		if !g.includeSnapshotTable(snapshotTable) {
+			continue
+		}
+
+		tableName := g.sourceTable(snapshotTable).String()
+
+		for _, column := range snapshotTable.Columns {
+			resolution := g.resolveAnonymizer(snapshotTable, column)
+
+			explanations = append(
+				explanations,
+				ColumnExplanation{
+					Table:   tableName,
+					Column:  column.Name,
+					Source:  resolution.source,
+					Rule:    resolution.rule,
+					Omitted: isOmitted(resolution.anonymizer),
+				},
+			)
+		}
+	}
+
+	return explanations
+}
@@ -0,0 +1,297 @@
+package gotidus
+
+import (
+	"testing"
+
+	"github.com/viafintech/gotidus/testutils"
+)
+
+func TestNameMatchers(t *testing.T) {
+	cases := []struct {
+		title   string
+		matcher NameMatcher
+		name    string
+
+		expectedMatch  bool
+		expectedString string
+	}{
+		{
+			title:   "exact name matches",
+			matcher: MatchName("email"),
+			name:    "email",
+
+			expectedMatch:  true,
+			expectedString: "email",
+		},
+		{
+			title:   "exact name does not match",
+			matcher: MatchName("email"),
+			name:    "contact_email",
+
+			expectedMatch:  false,
+			expectedString: "email",
+		},
+		{
+			title:   "glob matches",
+			matcher: MatchGlob("*_email"),
+			name:    "billing_email",
+
+			expectedMatch:  true,
+			expectedString: "glob:*_email",
+		},
+		{
+			title:   "glob does not match",
+			matcher: MatchGlob("*_email"),
+			name:    "email",
+
+			expectedMatch:  false,
+			expectedString: "glob:*_email",
+		},
+		{
+			title:   "regexp matches",
+			matcher: MatchRegexp("^(contact_|billing_)?email$"),
+			name:    "contact_email",
+
+			expectedMatch:  true,
+			expectedString: "regexp:^(contact_|billing_)?email$",
+		},
+		{
+			title:   "regexp does not match",
+			matcher: MatchRegexp("^(contact_|billing_)?email$"),
+			name:    "email_verified",
+
+			expectedMatch:  false,
+			expectedString: "regexp:^(contact_|billing_)?email$",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			testutils.CompareStructs(c.matcher.Match(c.name), c.expectedMatch, t)
+			testutils.CompareStrings(c.matcher.String(), c.expectedString, t)
+		})
+	}
+}
+
+func TestMatchGlobPanicsOnInvalidPattern(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected MatchGlob to panic")
+		}
+	}()
+
+	MatchGlob("[")
+}
+
+func TestGeneratorColumnRules(t *testing.T) {
+	snapshot := &Snapshot{
+		Tables: []SnapshotTable{
+			{
+				Schema: "public",
+				Name:   "users",
+				Columns: []Column{
+					{Name: "id", DataType: "integer", OrdinalPosition: 1},
+					{Name: "email", DataType: "text", OrdinalPosition: 2},
+					{Name: "contact_email", DataType: "text", OrdinalPosition: 3},
+				},
+			},
+			{
+				Schema: "public",
+				Name:   "invoices",
+				Columns: []Column{
+					{Name: "billing_email", DataType: "text", OrdinalPosition: 1},
+					{Name: "iban", DataType: "text", OrdinalPosition: 2},
+				},
+			},
+		},
+	}
+
+	generator := NewGenerator(&mockQueryBuilder{})
+	generator.
+		AddTable("users", NewTable().AddAnonymizer("email", NewStaticAnonymizer("table", "TEXT"))).
+		AddColumnRule(NewColumnRule(MatchGlob("*email"), NewStaticAnonymizer("email", "TEXT"))).
+		AddColumnRule(
+			NewColumnRule(MatchName("iban"), NewStaticAnonymizer("iban", "TEXT")).
+				ForTables(MatchRegexp("^public\\.invoices$")),
+		).
+		AddColumnRule(NewColumnRule(MatchName("billing_email"), NewStaticAnonymizer("unused", "TEXT")))
+
+	plan, err := generator.PlanSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("Failed to build plan: %+v", err)
+	}
+
+	testutils.CompareStrings(
+		plan.Statements[0].Query,
+		"create_view_query:users_anonymized;users;"+
+			"users.id AS id|'table'::TEXT AS email|'email'::TEXT AS contact_email",
+		t,
+	)
+	testutils.CompareStrings(
+		plan.Statements[1].Query,
+		"create_view_query:invoices_anonymized;invoices;"+
+			"'email'::TEXT AS billing_email|'iban'::TEXT AS iban",
+		t,
+	)
+
+	expectedExplanations := []ColumnExplanation{
+		{Table: "users", Column: "id", Source: AnonymizerSourceNone},
+		{Table: "users", Column: "email", Source: AnonymizerSourceTable},
+		{
+			Table:  "users",
+			Column: "contact_email",
+			Source: AnonymizerSourceColumnRule,
+			Rule:   "columns glob:*email",
+		},
+		{
+			Table:  "invoices",
+			Column: "billing_email",
+			Source: AnonymizerSourceColumnRule,
+			Rule:   "columns glob:*email",
+		},
+		{
+			Table:  "invoices",
+			Column: "iban",
+			Source: AnonymizerSourceColumnRule,
+			Rule:   "columns iban in tables regexp:^public\\.invoices$",
+		},
+	}
+
+	testutils.CompareStructs(generator.ExplainSnapshot(snapshot), expectedExplanations, t)
+}
+
+func TestTypeMatchers(t *testing.T) {
+	column := Column{
+		Name:         "address",
+		DataType:     "character varying",
+		UDTName:      "varchar",
+		TypeCategory: "S",
+		DomainName:   "email_address",
+	}
+
+	cases := []struct {
+		title   string
+		matcher TypeMatcher
+
+		expectedMatch  bool
+		expectedString string
+	}{
+		{
+			title:   "data type matches",
+			matcher: MatchType("CHARACTER VARYING"),
+
+			expectedMatch:  true,
+			expectedString: "type:CHARACTER VARYING",
+		},
+		{
+			title:   "underlying type name matches",
+			matcher: MatchType("varchar"),
+
+			expectedMatch:  true,
+			expectedString: "type:varchar",
+		},
+		{
+			title:   "type does not match",
+			matcher: MatchType("inet"),
+
+			expectedMatch:  false,
+			expectedString: "type:inet",
+		},
+		{
+			title:   "category matches",
+			matcher: MatchTypeCategory("S"),
+
+			expectedMatch:  true,
+			expectedString: "category:S",
+		},
+		{
+			title:   "category does not match",
+			matcher: MatchTypeCategory("N"),
+
+			expectedMatch:  false,
+			expectedString: "category:N",
+		},
+		{
+			title:   "domain matches",
+			matcher: MatchDomain("email_address"),
+
+			expectedMatch:  true,
+			expectedString: "domain:email_address",
+		},
+		{
+			title:   "domain does not match",
+			matcher: MatchDomain("iban"),
+
+			expectedMatch:  false,
+			expectedString: "domain:iban",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			testutils.CompareStructs(c.matcher.Match(column), c.expectedMatch, t)
+			testutils.CompareStrings(c.matcher.String(), c.expectedString, t)
+		})
+	}
+}
+
+func TestGeneratorTypeRules(t *testing.T) {
+	snapshot := &Snapshot{
+		Tables: []SnapshotTable{
+			{
+				Schema: "public",
+				Name:   "sessions",
+				Columns: []Column{
+					{Name: "ip", DataType: "inet", OrdinalPosition: 1, TypeCategory: "I"},
+					{Name: "data", DataType: "bytea", OrdinalPosition: 2, TypeCategory: "U"},
+					{Name: "token", DataType: "bytea", OrdinalPosition: 3, TypeCategory: "U"},
+					{Name: "email", DataType: "text", OrdinalPosition: 4, TypeCategory: "S"},
+				},
+			},
+		},
+	}
+
+	generator := NewGenerator(&mockQueryBuilder{})
+	generator.
+		AddTable("sessions", NewTable().AddAnonymizer("token", NewStaticAnonymizer("table", "TEXT"))).
+		AddColumnRule(NewColumnRule(MatchName("email"), NewStaticAnonymizer("email", "TEXT"))).
+		AddTypeRule(NewTypeRule(MatchTypeCategory("I"), NewStaticAnonymizer("0.0.0.0", "inet"))).
+		AddTypeRule(NewTypeRule(MatchType("bytea"), NewStaticAnonymizer("", "bytea"))).
+		AddTypeRule(NewTypeRule(MatchTypeCategory("S"), NewStaticAnonymizer("string", "TEXT")))
+
+	plan, err := generator.PlanSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("Failed to build plan: %+v", err)
+	}
+
+	testutils.CompareStrings(
+		plan.Statements[0].Query,
+		"create_view_query:sessions_anonymized;sessions;"+
+			"'0.0.0.0'::inet AS ip|''::bytea AS data|'table'::TEXT AS token|'email'::TEXT AS email",
+		t,
+	)
+
+	expectedExplanations := []ColumnExplanation{
+		{
+			Table:  "sessions",
+			Column: "ip",
+			Source: AnonymizerSourceTypeRule,
+			Rule:   "columns of category:I",
+		},
+		{
+			Table:  "sessions",
+			Column: "data",
+			Source: AnonymizerSourceTypeRule,
+			Rule:   "columns of type:bytea",
+		},
+		{Table: "sessions", Column: "token", Source: AnonymizerSourceTable},
+		{
+			Table:  "sessions",
+			Column: "email",
+			Source: AnonymizerSourceColumnRule,
+			Rule:   "columns email",
+		},
+	}
+
+	testutils.CompareStructs(generator.ExplainSnapshot(snapshot), expectedExplanations, t)
+}
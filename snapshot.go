@@ -0,0 +1,146 @@
+package gotidus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Column describes a column of a table as it exists in the database.
+// Default and CharacterMaximumLength are nil if the column has no default value
+// or no length limit respectively.
+// UDTName is the name of the underlying type, which is required to describe
+// array and user-defined types.
+// FormattedType is the full definition of the type including modifiers, e.g. character varying(64).
+// TypeCategory is the single character category of the type, e.g. N for numeric types,
+// and DomainName the name of the domain if the column is based on one.
+type Column struct {
+	Name                   string  `json:"name"`
+	DataType               string  `json:"data_type"`
+	OrdinalPosition        int     `json:"ordinal_position"`
+	IsNullable             bool    `json:"is_nullable,omitempty"`
+	Default                *string `json:"default,omitempty"`
+	CharacterMaximumLength *int    `json:"character_maximum_length,omitempty"`
+	UDTName                string  `json:"udt_name,omitempty"`
+	FormattedType          string  `json:"formatted_type,omitempty"`
+	TypeCategory           string  `json:"type_category,omitempty"`
+	DomainName             string  `json:"domain_name,omitempty"`
+}
+
+// TypeName returns the name of the column type, which can be used for casting values
+// to the type of the column.
+// If known, the full type definition is returned. Otherwise, the name of the underlying type
+// is returned for array and user-defined types, quoted with the given IdentifierQuoter.
+func (c Column) TypeName(quoter IdentifierQuoter) string {
+	if c.FormattedType != "" {
+		return c.FormattedType
+	}
+
+	if (c.DataType == "ARRAY" || c.DataType == "USER-DEFINED") && c.UDTName != "" {
+		return quoter.QuoteIdentifier(c.UDTName)
+	}
+
+	return c.DataType
+}
+
+// SnapshotTable describes a table and its columns as part of a Snapshot.
+type SnapshotTable struct {
+	Schema  string   `json:"schema"`
+	Name    string   `json:"name"`
+	Columns []Column `json:"columns"`
+}
+
+// Snapshot is a serializable description of the tables and columns of a database.
+// It allows building the views without a database connection through Generator.PlanSnapshot.
+type Snapshot struct {
+	Tables []SnapshotTable `json:"tables"`
+}
+
+// Snapshot reads the tables and columns from the database using the list queries
+// of the QueryBuilder.
+func (g *Generator) Snapshot(ctx context.Context, db Querier) (*Snapshot, error) {
+	snapshot := &Snapshot{
+		Tables: make([]SnapshotTable, 0),
+	}
+
+	if err := g.loopTables(
+		ctx,
+		db,
+		func(schema, tableName string) error {
+			snapshotTable := SnapshotTable{
+				Schema:  schema,
+				Name:    tableName,
+				Columns: make([]Column, 0),
+			}
+
+			if err := g.loopColumns(
+				ctx,
+				db,
+				schema,
+				tableName,
+				func(column Column) error {
+					snapshotTable.Columns = append(snapshotTable.Columns, column)
+
+					return nil
+				},
+			); err != nil {
+				return err
+			}
+
+			snapshot.Tables = append(snapshot.Tables, snapshotTable)
+
+			return nil
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// ReadSnapshot reads a Snapshot in the JSON format written by Snapshot.Write.
+// The columns of each table are ordered by their ordinal position.
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	snapshot := &Snapshot{}
+
+	if err := json.NewDecoder(r).Decode(snapshot); err != nil {
+		return nil, fmt.Errorf("Failed to read snapshot: %+v", err)
+	}
+
+	for _, table := range snapshot.Tables {
+		sort.SliceStable(table.Columns, func(i, j int) bool {
+			return table.Columns[i].OrdinalPosition < table.Columns[j].OrdinalPosition
+		})
+	}
+
+	return snapshot, nil
+}
+
+// Write writes the Snapshot as indented JSON, which is suitable for checking it into
+// version control.
+func (s *Snapshot) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(s)
+}
+
+// column returns the column of the given name and reports whether the table contains it.
+func (t SnapshotTable) column(name string) (Column, bool) {
+	for _, column := range t.Columns {
+		if column.Name == name {
+			return column, true
+		}
+	}
+
+	return Column{}, false
+}
+
+// hasColumn reports whether the table contains a column of the given name.
+func (t SnapshotTable) hasColumn(name string) bool {
+	_, ok := t.column(name)
+
+	return ok
+}
@@ -0,0 +1,740 @@
+package gotidus
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/viafintech/gotidus/testutils"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestGeneratorSnapshot(t *testing.T) {
+	queryBuilder := &mockQueryBuilder{}
+
+	db, dbMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to initialize DB mock")
+	}
+
+	tableRows := sqlmock.NewRows([]string{"schemaname", "tablename"})
+	tableRows.AddRow("public", "foo")
+
+	dbMock.
+		ExpectQuery(queryBuilder.ListTablesQuery()).
+		WithArgs("").
+		WillReturnRows(tableRows)
+
+	columnRows := newColumnRows()
+	columnRows.AddRow("id", "integer", 1, false, nil, nil, nil, nil, nil, nil)
+	columnRows.AddRow("bar", "character varying", 2, true, "'none'::character varying", 255, "varchar", "character varying(255)", nil, nil)
+
+	dbMock.
+		ExpectQuery(queryBuilder.ListColumnsQuery()).
+		WithArgs("public", "foo").
+		WillReturnRows(columnRows)
+
+	snapshot, err := NewGenerator(queryBuilder).Snapshot(context.Background(), db)
+	if err != nil {
+		t.Fatalf("Failed to take snapshot: %+v", err)
+	}
+
+	expectedSnapshot := &Snapshot{
+		Tables: []SnapshotTable{
+			{
+				Schema: "public",
+				Name:   "foo",
+				Columns: []Column{
+					{Name: "id", DataType: "integer", OrdinalPosition: 1},
+					{
+						Name:                   "bar",
+						DataType:               "character varying",
+						OrdinalPosition:        2,
+						IsNullable:             true,
+						Default:                stringPointer("'none'::character varying"),
+						CharacterMaximumLength: intPointer(255),
+						UDTName:                "varchar",
+						FormattedType:          "character varying(255)",
+					},
+				},
+			},
+		},
+	}
+
+	testutils.CompareStructs(snapshot, expectedSnapshot, t)
+
+	if err := dbMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Did not execute expected queries")
+	}
+}
+
+func TestSnapshotWriteAndRead(t *testing.T) {
+	snapshot := &Snapshot{
+		Tables: []SnapshotTable{
+			{
+				Schema: "public",
+				Name:   "foo",
+				Columns: []Column{
+					{Name: "id", DataType: "integer", OrdinalPosition: 1},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+
+	if err := snapshot.Write(&buf); err != nil {
+		t.Fatalf("Failed to write snapshot: %+v", err)
+	}
+
+	testutils.CompareStrings(
+		buf.String(),
+		`{
+  "tables": [
+    {
+      "schema": "public",
+      "name": "foo",
+      "columns": [
+        {
+          "name": "id",
+          "data_type": "integer",
+          "ordinal_position": 1
+        }
+      ]
+    }
+  ]
+}
+`,
+		t,
+	)
+
+	readSnapshot, err := ReadSnapshot(&buf)
+	if err != nil {
+		t.Fatalf("Failed to read snapshot: %+v", err)
+	}
+
+	testutils.CompareStructs(readSnapshot, snapshot, t)
+}
+
+func TestReadSnapshotOrdersColumns(t *testing.T) {
+	snapshot, err := ReadSnapshot(strings.NewReader(`{"tables": [{"schema": "public", "name": "foo", "columns": [
+		{"name": "bar", "data_type": "text", "ordinal_position": 2},
+		{"name": "id", "data_type": "integer", "ordinal_position": 1}
+	]}]}`))
+	if err != nil {
+		t.Fatalf("Failed to read snapshot: %+v", err)
+	}
+
+	testutils.CompareStructs(
+		snapshot.Tables[0].Columns,
+		[]Column{
+			{Name: "id", DataType: "integer", OrdinalPosition: 1},
+			{Name: "bar", DataType: "text", OrdinalPosition: 2},
+		},
+		t,
+	)
+}
+
+func TestGeneratorPlanSnapshot(t *testing.T) {
+	snapshot := &Snapshot{
+		Tables: []SnapshotTable{
+			{
+				Schema: "public",
+				Name:   "foo",
+				Columns: []Column{
+					{Name: "id", DataType: "integer", OrdinalPosition: 1},
+					{Name: "bar", DataType: "text", OrdinalPosition: 2},
+				},
+			},
+		},
+	}
+
+	generator := NewGenerator(&mockQueryBuilder{})
+	generator.AddTable("foo", NewTable().AddAnonymizer("bar", NewStaticAnonymizer("var", "TEXT")))
+
+	plan, err := generator.PlanSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("Failed to build plan: %+v", err)
+	}
+
+	expectedPlan := &Plan{
+		Statements: []Statement{
+			{
+				Kind:      StatementCreateView,
+				TableName: "foo",
+				ViewName:  "foo_anonymized",
+				Query:     "create_view_query:foo_anonymized;foo;foo.id AS id|'var'::TEXT AS bar",
+			},
+		},
+	}
+
+	testutils.CompareStructs(plan, expectedPlan, t)
+}
+
+func TestGeneratorPlanSnapshotWithSchemas(t *testing.T) {
+	snapshot := &Snapshot{
+		Tables: []SnapshotTable{
+			{
+				Schema: "billing",
+				Name:   "users",
+				Columns: []Column{
+					{Name: "iban", DataType: "text", OrdinalPosition: 1},
+				},
+			},
+			{
+				Schema: "crm",
+				Name:   "users",
+				Columns: []Column{
+					{Name: "email", DataType: "text", OrdinalPosition: 1},
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		title   string
+		options []GeneratorOption
+
+		expectedPlan  *Plan
+		expectedError error
+	}{
+		{
+			title: "views are created in the source schemas",
+			options: []GeneratorOption{
+				WithSourceSchemas("billing", "crm"),
+			},
+
+			expectedPlan: &Plan{
+				Statements: []Statement{
+					{
+						Kind:      StatementCreateView,
+						TableName: "billing.users",
+						ViewName:  "billing.users_anonymized",
+						Query: "create_view_query:billing.users_anonymized;billing.users;" +
+							"'static'::TEXT AS iban",
+					},
+					{
+						Kind:      StatementCreateView,
+						TableName: "crm.users",
+						ViewName:  "crm.users_anonymized",
+						Query:     "create_view_query:crm.users_anonymized;crm.users;users.email AS email",
+					},
+				},
+			},
+		},
+		{
+			title: "views of same named tables conflict in the target schema",
+			options: []GeneratorOption{
+				WithSourceSchemas("billing", "crm"),
+				WithTargetSchema("anonymized"),
+			},
+
+			expectedError: errors.New(
+				"Tables 'billing.users' and 'crm.users' would both create view 'anonymized.users'",
+			),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			generator := NewGenerator(&mockQueryBuilder{}, c.options...)
+			generator.AddTable(
+				"billing.users",
+				NewTable().AddAnonymizer("iban", NewStaticAnonymizer("static", "TEXT")),
+			)
+
+			plan, err := generator.PlanSnapshot(snapshot)
+
+			testutils.CompareStructs(err, c.expectedError, t)
+			testutils.CompareStructs(plan, c.expectedPlan, t)
+		})
+	}
+}
+
+func TestGeneratorPlanSnapshotQuotesIdentifiers(t *testing.T) {
+	snapshot := &Snapshot{
+		Tables: []SnapshotTable{
+			{
+				Schema: "Sales",
+				Name:   "order",
+				Columns: []Column{
+					{Name: "user", DataType: "text", OrdinalPosition: 1},
+					{Name: "Total Amount", DataType: "numeric", OrdinalPosition: 2},
+				},
+			},
+		},
+	}
+
+	generator := NewGenerator(&mockQueryBuilder{}, WithSourceSchemas("Sales"))
+
+	plan, err := generator.PlanSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("Failed to build plan: %+v", err)
+	}
+
+	expectedPlan := &Plan{
+		Statements: []Statement{
+			{
+				Kind:      StatementCreateView,
+				TableName: "Sales.order",
+				ViewName:  "Sales.order_anonymized",
+				Query: `create_view_query:"Sales".order_anonymized;"Sales"."order";` +
+					`"order"."user" AS "user"|"order"."Total Amount" AS "Total Amount"`,
+			},
+		},
+	}
+
+	testutils.CompareStructs(plan, expectedPlan, t)
+}
+
+func TestGeneratorPlanSnapshotWithDefaultAnonymizer(t *testing.T) {
+	snapshot := &Snapshot{
+		Tables: []SnapshotTable{
+			{
+				Schema: "public",
+				Name:   "foo",
+				Columns: []Column{
+					{Name: "id", DataType: "integer", OrdinalPosition: 1},
+					{Name: "bar", DataType: "text", OrdinalPosition: 2},
+					{Name: "baz", DataType: "text", OrdinalPosition: 3},
+				},
+			},
+		},
+	}
+
+	generator := NewGenerator(
+		&mockQueryBuilder{},
+		WithDefaultAnonymizer(NewStaticAnonymizer("redacted", "TEXT")),
+	)
+	generator.AddTable(
+		"foo",
+		NewTable().
+			Allow("id").
+			AddAnonymizer("bar", NewStaticAnonymizer("var", "TEXT")),
+	)
+
+	plan, err := generator.PlanSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("Failed to build plan: %+v", err)
+	}
+
+	expectedPlan := &Plan{
+		Statements: []Statement{
+			{
+				Kind:      StatementCreateView,
+				TableName: "foo",
+				ViewName:  "foo_anonymized",
+				Query: "create_view_query:foo_anonymized;foo;" +
+					"foo.id AS id|'var'::TEXT AS bar|'redacted'::TEXT AS baz",
+			},
+		},
+	}
+
+	testutils.CompareStructs(plan, expectedPlan, t)
+}
+
+func TestGeneratorPlanSnapshotWithColumnAwareAnonymizer(t *testing.T) {
+	snapshot := &Snapshot{
+		Tables: []SnapshotTable{
+			{
+				Schema: "public",
+				Name:   "foo",
+				Columns: []Column{
+					{Name: "id", DataType: "integer", OrdinalPosition: 1},
+					{Name: "bar", DataType: "ARRAY", OrdinalPosition: 2, UDTName: "_text"},
+				},
+			},
+		},
+	}
+
+	generator := NewGenerator(&mockQueryBuilder{})
+	generator.AddTable(
+		"foo",
+		NewTable().
+			AddAnonymizer("id", NewStaticAnonymizer("0", "")).
+			AddAnonymizer("bar", NewStaticAnonymizer("{}", "")),
+	)
+
+	plan, err := generator.PlanSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("Failed to build plan: %+v", err)
+	}
+
+	testutils.CompareStrings(
+		plan.Statements[0].Query,
+		"create_view_query:foo_anonymized;foo;'0'::integer AS id|'{}'::_text AS bar",
+		t,
+	)
+}
+
+func TestGeneratorPlanSnapshotWithTypePreservingCasts(t *testing.T) {
+	snapshot := &Snapshot{
+		Tables: []SnapshotTable{
+			{
+				Schema: "public",
+				Name:   "foo",
+				Columns: []Column{
+					{Name: "id", DataType: "integer", OrdinalPosition: 1, FormattedType: "integer"},
+					{
+						Name:            "email",
+						DataType:        "character varying",
+						OrdinalPosition: 2,
+						FormattedType:   "character varying(64)",
+					},
+					{Name: "iban", DataType: "text", OrdinalPosition: 3, FormattedType: "text"},
+					{Name: "note", DataType: "text", OrdinalPosition: 4, FormattedType: "text"},
+				},
+			},
+		},
+	}
+
+	generator := NewGenerator(&mockQueryBuilder{}, WithTypePreservingCasts())
+	generator.AddTable(
+		"foo",
+		NewTable().
+			Allow("id").
+			AddAnonymizer("email", NewStaticAnonymizer("hidden", "TEXT")).
+			AddAnonymizer("iban", WithoutTypeCast(NewStaticAnonymizer("hidden", "TEXT"))),
+	)
+
+	plan, err := generator.PlanSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("Failed to build plan: %+v", err)
+	}
+
+	testutils.CompareStrings(
+		plan.Statements[0].Query,
+		"create_view_query:foo_anonymized;foo;"+
+			"foo.id AS id|"+
+			"cast_query:'hidden'::TEXT;character varying(64) AS email|"+
+			"'hidden'::TEXT AS iban|"+
+			"foo.note AS note",
+		t,
+	)
+}
+
+func TestGeneratorPlanSnapshotWithTableFilters(t *testing.T) {
+	snapshot := &Snapshot{
+		Tables: []SnapshotTable{
+			{Schema: "public", Name: "jobs", Columns: []Column{{Name: "id", DataType: "integer"}}},
+			{Schema: "public", Name: "users", Columns: []Column{{Name: "id", DataType: "integer"}}},
+			{Schema: "billing", Name: "users", Columns: []Column{{Name: "id", DataType: "integer"}}},
+			{Schema: "billing", Name: "invoices", Columns: []Column{{Name: "id", DataType: "integer"}}},
+		},
+	}
+
+	cases := []struct {
+		title   string
+		options []GeneratorOption
+
+		expectedViews []string
+	}{
+		{
+			title: "without filters",
+
+			expectedViews: []string{
+				"public.jobs_anonymized",
+				"public.users_anonymized",
+				"billing.users_anonymized",
+				"billing.invoices_anonymized",
+			},
+		},
+		{
+			title: "included tables",
+			options: []GeneratorOption{
+				WithIncludedTables(MatchName("users"), MatchGlob("billing.inv*")),
+			},
+
+			expectedViews: []string{
+				"public.users_anonymized",
+				"billing.users_anonymized",
+				"billing.invoices_anonymized",
+			},
+		},
+		{
+			title: "excluded tables",
+			options: []GeneratorOption{
+				WithExcludedTables(MatchName("jobs"), MatchName("billing.users")),
+			},
+
+			expectedViews: []string{
+				"public.users_anonymized",
+				"billing.invoices_anonymized",
+			},
+		},
+		{
+			title: "excluded tables take precedence over included tables",
+			options: []GeneratorOption{
+				WithIncludedTables(MatchGlob("billing.*")),
+				WithExcludedTables(MatchName("invoices")),
+			},
+
+			expectedViews: []string{
+				"billing.users_anonymized",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			options := append([]GeneratorOption{WithSourceSchemas("public", "billing")}, c.options...)
+
+			plan, err := NewGenerator(&mockQueryBuilder{}, options...).PlanSnapshot(snapshot)
+			if err != nil {
+				t.Fatalf("Failed to build plan: %+v", err)
+			}
+
+			views := make([]string, 0)
+			for _, statement := range plan.Statements {
+				views = append(views, statement.ViewName)
+			}
+
+			testutils.CompareStructs(views, c.expectedViews, t)
+		})
+	}
+}
+
+func TestGeneratorPlanSnapshotWithOmittedColumns(t *testing.T) {
+	snapshot := &Snapshot{
+		Tables: []SnapshotTable{
+			{
+				Schema: "public",
+				Name:   "documents",
+				Columns: []Column{
+					{Name: "id", DataType: "integer", OrdinalPosition: 1},
+					{Name: "scan", DataType: "bytea", OrdinalPosition: 2},
+					{Name: "api_secret", DataType: "text", OrdinalPosition: 3},
+					{Name: "title", DataType: "text", OrdinalPosition: 4},
+				},
+			},
+		},
+	}
+
+	generator := NewGenerator(&mockQueryBuilder{}, WithDefaultAnonymizer(NewOmitAnonymizer()))
+	generator.
+		AddTable("documents", NewTable().Allow("id", "title").OmitColumn("scan")).
+		AddColumnRule(NewColumnRule(MatchGlob("*_secret"), NewOmitAnonymizer()))
+
+	plan, err := generator.PlanSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("Failed to build plan: %+v", err)
+	}
+
+	expectedPlan := &Plan{
+		Statements: []Statement{
+			{
+				Kind:      StatementCreateView,
+				TableName: "documents",
+				ViewName:  "documents_anonymized",
+				Query: "create_view_query:documents_anonymized;documents;" +
+					"documents.id AS id|documents.title AS title",
+				OmittedColumns: []string{"scan", "api_secret"},
+			},
+		},
+	}
+
+	testutils.CompareStructs(plan, expectedPlan, t)
+
+	expectedExplanations := []ColumnExplanation{
+		{Table: "documents", Column: "id", Source: AnonymizerSourceTable},
+		{Table: "documents", Column: "scan", Source: AnonymizerSourceTable, Omitted: true},
+		{
+			Table:   "documents",
+			Column:  "api_secret",
+			Source:  AnonymizerSourceColumnRule,
+			Rule:    "columns glob:*_secret",
+			Omitted: true,
+		},
+		{Table: "documents", Column: "title", Source: AnonymizerSourceTable},
+	}
+
+	testutils.CompareStructs(generator.ExplainSnapshot(snapshot), expectedExplanations, t)
+}
+
+func TestGeneratorPlanSnapshotWithComputedColumns(t *testing.T) {
+	snapshot := &Snapshot{
+		Tables: []SnapshotTable{
+			{
+				Schema: "public",
+				Name:   "users",
+				Columns: []Column{
+					{Name: "id", DataType: "integer", OrdinalPosition: 1},
+					{Name: "email", DataType: "text", OrdinalPosition: 2},
+					{Name: "dob", DataType: "date", OrdinalPosition: 3},
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		title string
+		table *Table
+
+		expectedQuery string
+		expectedError error
+	}{
+		{
+			title: "computed and renamed columns",
+			table: NewTable().
+				OmitColumn("email").
+				RenameColumn("id", "user_id").
+				AddComputedColumn(
+					"email_domain",
+					"email",
+					NewExpressionAnonymizer("SPLIT_PART({column}, '@', 2)"),
+				).
+				AddComputedColumn("birth_year", "dob", NewExpressionAnonymizer("DATE_PART('year', {column})")).
+				AddComputedColumn("missing", "missing", NewNoopAnonymizer()),
+
+			expectedQuery: "create_view_query:users_anonymized;users;" +
+				"users.id AS user_id|users.dob AS dob|" +
+				"SPLIT_PART(users.email, '@', 2) AS email_domain|" +
+				"DATE_PART('year', users.dob) AS birth_year",
+		},
+		{
+			title: "computed column conflicts with column",
+			table: NewTable().
+				AddComputedColumn("dob", "dob", NewExpressionAnonymizer("DATE_TRUNC('year', {column})")),
+
+			expectedError: errors.New("Column 'dob' is defined more than once in the view of table 'users'"),
+		},
+		{
+			title: "renamed column conflicts with column",
+			table: NewTable().RenameColumn("dob", "email"),
+
+			expectedError: errors.New("Column 'email' is defined more than once in the view of table 'users'"),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			generator := NewGenerator(&mockQueryBuilder{})
+			generator.AddTable("users", c.table)
+
+			plan, err := generator.PlanSnapshot(snapshot)
+
+			testutils.CompareStructs(err, c.expectedError, t)
+
+			if err == nil {
+				testutils.CompareStrings(plan.Statements[0].Query, c.expectedQuery, t)
+			}
+		})
+	}
+}
+
+func TestGeneratorPlanSnapshotWithUnmaskRoles(t *testing.T) {
+	snapshot := &Snapshot{
+		Tables: []SnapshotTable{
+			{
+				Schema: "public",
+				Name:   "users",
+				Columns: []Column{
+					{Name: "id", DataType: "integer", OrdinalPosition: 1},
+					{Name: "email", DataType: "text", OrdinalPosition: 2},
+					{Name: "iban", DataType: "text", OrdinalPosition: 3},
+					{Name: "password", DataType: "text", OrdinalPosition: 4},
+				},
+			},
+			{
+				Schema: "public",
+				Name:   "invoices",
+				Columns: []Column{
+					{Name: "amount", DataType: "numeric", OrdinalPosition: 1},
+				},
+			},
+		},
+	}
+
+	static := NewStaticAnonymizer("hidden", "TEXT")
+
+	generator := NewGenerator(&mockQueryBuilder{}, WithUnmaskRole("support"))
+	generator.AddTable(
+		"users",
+		NewTable().
+			AddAnonymizer("email", static).
+			AddAnonymizer("iban", static).
+			AddAnonymizer("password", static).
+			WithUnmaskRole("admin").
+			WithColumnUnmaskRole("iban", "finance").
+			WithColumnUnmaskRole("password", ""),
+	)
+	generator.AddTable("invoices", NewTable().AddAnonymizer("amount", NewStaticAnonymizer("0", "numeric")))
+
+	plan, err := generator.PlanSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("Failed to build plan: %+v", err)
+	}
+
+	testutils.CompareStrings(
+		plan.Statements[0].Query,
+		"create_view_query:users_anonymized;users;"+
+			"users.id AS id|"+
+			"unmask_query:admin;users.email;'hidden'::TEXT AS email|"+
+			"unmask_query:finance;users.iban;'hidden'::TEXT AS iban|"+
+			"'hidden'::TEXT AS password",
+		t,
+	)
+	testutils.CompareStrings(
+		plan.Statements[1].Query,
+		"create_view_query:invoices_anonymized;invoices;"+
+			"unmask_query:support;invoices.amount;'0'::numeric AS amount",
+		t,
+	)
+}
+
+func TestGeneratorPlanSnapshotWithWrappedAnonymizers(t *testing.T) {
+	snapshot := &Snapshot{
+		Tables: []SnapshotTable{
+			{
+				Schema: "public",
+				Name:   "documents",
+				Columns: []Column{
+					{Name: "id", DataType: "integer", OrdinalPosition: 1},
+					{Name: "scan", DataType: "bytea", OrdinalPosition: 2},
+				},
+			},
+		},
+	}
+
+	generator := NewGenerator(&mockQueryBuilder{}, WithTypePreservingCasts(), WithUnmaskRole("support"))
+	generator.AddTable(
+		"documents",
+		NewTable().
+			AddAnonymizer("id", WithoutTypeCast(NewPassthroughAnonymizer())).
+			AddAnonymizer("scan", WithoutTypeCast(NewOmitAnonymizer())),
+	)
+
+	plan, err := generator.PlanSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("Failed to build plan: %+v", err)
+	}
+
+	expectedStatements := []Statement{
+		{
+			Kind:           StatementCreateView,
+			TableName:      "documents",
+			ViewName:       "documents_anonymized",
+			Query:          "create_view_query:documents_anonymized;documents;documents.id AS id",
+			OmittedColumns: []string{"scan"},
+		},
+	}
+
+	testutils.CompareStructs(plan.Statements, expectedStatements, t)
+
+	expectedExplanations := []ColumnExplanation{
+		{Table: "documents", Column: "id", Source: AnonymizerSourceTable},
+		{Table: "documents", Column: "scan", Source: AnonymizerSourceTable, Omitted: true},
+	}
+
+	testutils.CompareStructs(generator.ExplainSnapshot(snapshot), expectedExplanations, t)
+}
+
+func stringPointer(s string) *string {
+	return &s
+}
+
+func intPointer(i int) *int {
+	return &i
+}
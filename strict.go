@@ -0,0 +1,127 @@
+package gotidus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MissingColumn identifies a configured column, which does not exist in its table.
+// Table is the name the Table configuration was added with.
+// Columns of the unique key are identified by their name within the view.
+type MissingColumn struct {
+	Table  string
+	Column string
+}
+
+// MissingConfigError is returned in strict mode if the configuration references
+// tables or columns, which do not exist in the database.
+// Tables contains the names of the configured tables that could not be found
+// and Columns the configured columns missing in the tables they were configured for.
+type MissingConfigError struct {
+	Tables  []string
+	Columns []MissingColumn
+}
+
+// Error lists all missing tables and columns.
+func (e *MissingConfigError) Error() string {
+	missing := make([]string, 0, len(e.Tables)+len(e.Columns))
+
+	for _, table := range e.Tables {
+		missing = append(missing, fmt.Sprintf("table '%s'", table))
+	}
+
+	for _, column := range e.Columns {
+		missing = append(missing, fmt.Sprintf("column '%s.%s'", column.Table, column.Column))
+	}
+
+	return fmt.Sprintf("Configuration references missing objects: %s", strings.Join(missing, ", "))
+}
+
+// checkConfig verifies that every configured table and column exists in the snapshot
+// and that every column of the unique key exists in the view of the table.
+// It returns a *MissingConfigError listing every table and column that could not be found.
+func (g *Generator) checkConfig(snapshot *Snapshot) error {
+	// Each configured table is checked against the snapshot tables it is applied to.
+	appliedTables := make(map[string][]SnapshotTable)
+
+	for _, snapshotTable := range snapshot.Tables {
+		name := qualifiedName(snapshotTable.Schema, snapshotTable.Name)
+		if _, ok := g.tables[name]; !ok {
+			name = snapshotTable.Name
+		}
+
+		appliedTables[name] = append(appliedTables[name], snapshotTable)
+	}
+
+	names := make([]string, 0, len(g.tables))
+	for name := range g.tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	missingErr := &MissingConfigError{
+		Tables:  make([]string, 0),
+		Columns: make([]MissingColumn, 0),
+	}
+
+	for _, name := range names {
+		snapshotTables, ok := appliedTables[name]
+		if !ok {
+			missingErr.Tables = append(missingErr.Tables, name)
+			continue
+		}
+
+		for _, columnName := range g.tables[name].columnNames() {
+			for _, snapshotTable := range snapshotTables {
+				if !snapshotTable.hasColumn(columnName) {
+					missingErr.Columns = append(
+						missingErr.Columns,
+						MissingColumn{Table: name, Column: columnName},
+					)
+					break
+				}
+			}
+		}
+
+		for _, columnName := range g.tables[name].uniqueKey {
+			for _, snapshotTable := range snapshotTables {
+				if !g.hasViewColumn(g.tables[name], snapshotTable, columnName) {
+					missingErr.Columns = append(
+						missingErr.Columns,
+						MissingColumn{Table: name, Column: columnName},
+					)
+					break
+				}
+			}
+		}
+	}
+
+	if len(missingErr.Tables) == 0 && len(missingErr.Columns) == 0 {
+		return nil
+	}
+
+	return missingErr
+}
+
+// hasViewColumn reports whether the view of the snapshot table exposes a column of the given name,
+// taking omitted, renamed and computed columns into account.
+func (g *Generator) hasViewColumn(table *Table, snapshotTable SnapshotTable, name string) bool {
+	for _, computed := range table.computedColumns {
+		if computed.name == name && snapshotTable.hasColumn(computed.sourceColumn) {
+			return true
+		}
+	}
+
+	for _, column := range snapshotTable.Columns {
+		if table.outputName(column.Name) != name {
+			continue
+		}
+
+		if !isOmitted(g.resolveAnonymizer(snapshotTable, column).anonymizer) {
+			return true
+		}
+	}
+
+	return false
+}
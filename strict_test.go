@@ -0,0 +1,201 @@
+package gotidus
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/viafintech/gotidus/testutils"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestMissingConfigErrorError(t *testing.T) {
+	err := &MissingConfigError{
+		Tables: []string{"foo"},
+		Columns: []MissingColumn{
+			{Table: "bar", Column: "iban"},
+		},
+	}
+
+	testutils.CompareStrings(
+		err.Error(),
+		"Configuration references missing objects: table 'foo', column 'bar.iban'",
+		t,
+	)
+}
+
+func TestGeneratorPlanSnapshotWithStrictConfig(t *testing.T) {
+	snapshot := &Snapshot{
+		Tables: []SnapshotTable{
+			{
+				Schema: "billing",
+				Name:   "users",
+				Columns: []Column{
+					{Name: "id", DataType: "integer", OrdinalPosition: 1},
+					{Name: "iban", DataType: "text", OrdinalPosition: 2},
+				},
+			},
+			{
+				Schema: "crm",
+				Name:   "users",
+				Columns: []Column{
+					{Name: "id", DataType: "integer", OrdinalPosition: 1},
+					{Name: "email", DataType: "text", OrdinalPosition: 2},
+				},
+			},
+		},
+	}
+
+	static := NewStaticAnonymizer("static", "TEXT")
+
+	cases := []struct {
+		title     string
+		setupFunc func(*Generator)
+
+		expectedError error
+	}{
+		{
+			title: "all configured tables and columns exist",
+			setupFunc: func(g *Generator) {
+				g.AddTable("users", NewTable().AddAnonymizer("id", static))
+				g.AddTable("billing.users", NewTable().AddAnonymizer("iban", static))
+			},
+		},
+		{
+			title: "missing tables and columns are listed",
+			setupFunc: func(g *Generator) {
+				g.AddTable("accounts", NewTable())
+				g.AddTable("billing.users", NewTable().AddAnonymizer("iban_number", static))
+				g.AddTable("crm.invoices", NewTable())
+			},
+
+			expectedError: &MissingConfigError{
+				Tables: []string{"accounts", "crm.invoices"},
+				Columns: []MissingColumn{
+					{Table: "billing.users", Column: "iban_number"},
+				},
+			},
+		},
+		{
+			title: "unqualified tables require the column in every schema",
+			setupFunc: func(g *Generator) {
+				g.AddTable("users", NewTable().AddAnonymizer("email", static))
+			},
+
+			expectedError: &MissingConfigError{
+				Tables: []string{},
+				Columns: []MissingColumn{
+					{Table: "users", Column: "email"},
+				},
+			},
+		},
+		{
+			title: "columns with unmask roles are checked",
+			setupFunc: func(g *Generator) {
+				g.AddTable("billing.users", NewTable().WithColumnUnmaskRole("iban_number", "support"))
+			},
+
+			expectedError: &MissingConfigError{
+				Tables: []string{},
+				Columns: []MissingColumn{
+					{Table: "billing.users", Column: "iban_number"},
+				},
+			},
+		},
+		{
+			title: "unique keys are checked against the columns of the view",
+			setupFunc: func(g *Generator) {
+				g.AddTable(
+					"billing.users",
+					NewTable().
+						RenameColumn("id", "user_id").
+						OmitColumn("iban").
+						WithUniqueKey("user_id", "id", "iban"),
+				)
+			},
+
+			expectedError: &MissingConfigError{
+				Tables: []string{},
+				Columns: []MissingColumn{
+					{Table: "billing.users", Column: "id"},
+					{Table: "billing.users", Column: "iban"},
+				},
+			},
+		},
+		{
+			title: "unqualified tables are only checked where they apply",
+			setupFunc: func(g *Generator) {
+				g.AddTable("users", NewTable().AddAnonymizer("email", static))
+				g.AddTable("billing.users", NewTable())
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			generator := NewGenerator(
+				&mockQueryBuilder{},
+				WithSourceSchemas("billing", "crm"),
+				WithStrictConfig(),
+			)
+			c.setupFunc(generator)
+
+			_, err := generator.PlanSnapshot(snapshot)
+
+			testutils.CompareStructs(err, c.expectedError, t)
+		})
+	}
+}
+
+func TestGeneratorCreateViewsWithStrictConfig(t *testing.T) {
+	queryBuilder := &mockQueryBuilder{}
+
+	db, dbMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to initialize DB mock")
+	}
+
+	dbMock.ExpectBegin()
+
+	tableRows := sqlmock.NewRows([]string{"schemaname", "tablename"})
+	tableRows.AddRow("public", "foo")
+
+	dbMock.
+		ExpectQuery(queryBuilder.ListViewsQuery()).
+		WithArgs("", "anonymized").
+		WillReturnRows(sqlmock.NewRows([]string{"viewname", "materialized"}))
+
+	dbMock.
+		ExpectQuery(queryBuilder.ListTablesQuery()).
+		WithArgs("").
+		WillReturnRows(tableRows)
+
+	columnRows := newColumnRows()
+	columnRows.AddRow("iban_number", "text", 1, false, nil, nil, nil, nil, nil, nil)
+
+	dbMock.
+		ExpectQuery(queryBuilder.ListColumnsQuery()).
+		WithArgs("public", "foo").
+		WillReturnRows(columnRows)
+
+	dbMock.ExpectRollback()
+
+	generator := NewGenerator(queryBuilder, WithStrictConfig())
+	generator.AddTable("foo", NewTable().AddAnonymizer("iban", NewNoopAnonymizer()))
+
+	err = generator.CreateViews(db)
+
+	var missingErr *MissingConfigError
+	if !errors.As(err, &missingErr) {
+		t.Fatalf("Expected *MissingConfigError, got %+v", err)
+	}
+
+	testutils.CompareStructs(
+		missingErr.Columns,
+		[]MissingColumn{{Table: "foo", Column: "iban"}},
+		t,
+	)
+
+	if err := dbMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Did not execute expected queries: %+v", err)
+	}
+}
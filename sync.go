@@ -0,0 +1,239 @@
+package gotidus
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+)
+
+// fingerprintPrefix marks view comments holding a fingerprint written by gotidus.
+const fingerprintPrefix = "gotidus:sha256:"
+
+// fingerprint builds the value stored as view comment to detect changes of the view definition.
+func fingerprint(query string) string {
+	sum := sha256.Sum256([]byte(query))
+
+	return fingerprintPrefix + hex.EncodeToString(sum[:])
+}
+
+// SyncReport lists the views affected by SyncViews, grouped by the action taken.
+type SyncReport struct {
+	Created   []string
+	Replaced  []string
+	Dropped   []string
+	Unchanged []string
+}
+
+func newSyncReport() *SyncReport {
+	return &SyncReport{
+		Created:   make([]string, 0),
+		Replaced:  make([]string, 0),
+		Dropped:   make([]string, 0),
+		Unchanged: make([]string, 0),
+	}
+}
+
+// SyncViews only touches the views that require a change instead of regenerating all views.
+// Views whose table no longer exists are dropped, missing views are created
+// and views whose definition changed are replaced. All other views are left unchanged.
+// Changes are detected through a fingerprint of the view definition, which is stored
+// as comment on the view. Views without a fingerprint, e.g. created by CreateViews,
+// are therefore replaced once. Grants of unchanged views are updated without replacing them.
+func (g *Generator) SyncViews(ctx context.Context, db Querier) (*SyncReport, error) {
+	var report *SyncReport
+
+	if err := inTransaction(
+		ctx,
+		db,
+		func(q Querier) error {
+			plan, syncReport, err := g.PlanSync(ctx, q)
+			if err != nil {
+				return err
+			}
+
+			report = syncReport
+
+			return plan.apply(ctx, q)
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// PlanSync builds the statements SyncViews would execute without executing them,
+// along with the report of the affected views.
+func (g *Generator) PlanSync(ctx context.Context, db Querier) (*Plan, *SyncReport, error) {
+	queryBuilder, ok := g.queryBuilder.(SyncQueryBuilder)
+	if !ok {
+		return nil, nil, unsupportedError("SyncQueryBuilder", "syncing views")
+	}
+
+	if err := g.checkServerVersion(ctx, db); err != nil {
+		return nil, nil, err
+	}
+
+	fingerprints, err := g.existingFingerprints(ctx, db, queryBuilder)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	grantees, err := g.existingGrantees(ctx, db)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	snapshot, err := g.Snapshot(ctx, db)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	definitions, err := g.viewDefinitions(snapshot)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plan := NewPlan()
+	report := newSyncReport()
+
+	desiredViews := make(map[objectName]bool)
+	for _, definition := range definitions {
+		desiredViews[definition.view] = true
+	}
+
+	for _, view := range fingerprints.views {
+		if desiredViews[view] {
+			continue
+		}
+
+		if err := g.planDropView(plan, g.tableName(view).String(), view, fingerprints.materialized[view]); err != nil {
+			return nil, nil, err
+		}
+
+		report.Dropped = append(report.Dropped, view.String())
+	}
+
+	for _, definition := range definitions {
+		tableName := definition.table.String()
+		viewName := definition.view.String()
+		viewFingerprint := fingerprint(definition.query + definition.indexQuery)
+
+		existingFingerprint, exists := fingerprints.byView[definition.view]
+
+		switch {
+		case !exists:
+			report.Created = append(report.Created, viewName)
+		case existingFingerprint == viewFingerprint:
+			report.Unchanged = append(report.Unchanged, viewName)
+
+			if err := g.planGrants(plan, definition, grantees[definition.view]); err != nil {
+				return nil, nil, err
+			}
+
+			continue
+		default:
+			// The view is dropped first, as replacing a view fails
+			// if columns were removed or changed their type.
+			if err := g.planDropView(
+				plan,
+				tableName,
+				definition.view,
+				fingerprints.materialized[definition.view],
+			); err != nil {
+				return nil, nil, err
+			}
+
+			report.Replaced = append(report.Replaced, viewName)
+		}
+
+		plan.addCreateView(definition)
+
+		commentQuery := queryBuilder.CommentViewQuery(g.quoteName(definition.view), viewFingerprint)
+		if definition.materialized {
+			materializedQueryBuilder, ok := g.queryBuilder.(MaterializedViewQueryBuilder)
+			if !ok {
+				return nil, nil, unsupportedError("MaterializedViewQueryBuilder", "materialized views")
+			}
+
+			commentQuery = materializedQueryBuilder.CommentMaterializedViewQuery(
+				g.quoteName(definition.view),
+				viewFingerprint,
+			)
+		}
+
+		plan.add(StatementCommentView, tableName, viewName, commentQuery)
+
+		// Created views have no grants and replaced views lose them when being dropped.
+		if err := g.planGrants(plan, definition, nil); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return plan, report, nil
+}
+
+type viewFingerprints struct {
+	views        []objectName
+	byView       map[objectName]string
+	materialized map[objectName]bool
+}
+
+func (g *Generator) existingFingerprints(
+	ctx context.Context,
+	q Querier,
+	queryBuilder SyncQueryBuilder,
+) (*viewFingerprints, error) {
+	fingerprints := &viewFingerprints{
+		views:        make([]objectName, 0),
+		byView:       make(map[objectName]string),
+		materialized: make(map[objectName]bool),
+	}
+
+	// Only QueryBuilders supporting materialized views return the kind of the views.
+	_, listsKinds := g.queryBuilder.(MaterializedViewQueryBuilder)
+
+	for _, schema := range g.viewSchemas() {
+		rows, err := q.QueryContext(
+			ctx,
+			queryBuilder.ListViewFingerprintsQuery(),
+			schema,
+			g.listedViewPostfix(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to select views: %+v", err)
+		}
+
+		for rows.Next() {
+			var (
+				viewName     string
+				comment      sql.NullString
+				materialized bool
+			)
+
+			destinations := []interface{}{&viewName, &comment}
+			if listsKinds {
+				destinations = append(destinations, &materialized)
+			}
+
+			if err := rows.Scan(destinations...); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("Failed to scan viewname: %+v", err)
+			}
+
+			view := objectName{schema: schema, name: viewName}
+
+			fingerprints.views = append(fingerprints.views, view)
+			fingerprints.byView[view] = comment.String
+			fingerprints.materialized[view] = materialized
+		}
+
+		if err := closeRows(rows); err != nil {
+			return nil, fmt.Errorf("Failed to select views: %+v", err)
+		}
+	}
+
+	return fingerprints, nil
+}
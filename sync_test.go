@@ -0,0 +1,235 @@
+package gotidus
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/viafintech/gotidus/testutils"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestFingerprint(t *testing.T) {
+	testutils.CompareStrings(
+		fingerprint("SELECT 1"),
+		"gotidus:sha256:e004ebd5b5532a4b85984a62f8ad48a81aa3460c1ca07701f386135d72cdecf5",
+		t,
+	)
+}
+
+func TestGeneratorSyncViews(t *testing.T) {
+	queryBuilder := &mockQueryBuilder{}
+
+	fooQuery := queryBuilder.CreateViewQuery("foo_anonymized", "foo", []string{"foo.id AS id"})
+	barQuery := queryBuilder.CreateViewQuery("bar_anonymized", "bar", []string{"bar.id AS id"})
+	bazQuery := queryBuilder.CreateViewQuery("baz_anonymized", "baz", []string{"baz.id AS id"})
+
+	setupIntrospection := func(mock sqlmock.Sqlmock) {
+		viewRows := sqlmock.NewRows([]string{"relname", "obj_description", "materialized"})
+		viewRows.AddRow("bar_anonymized", "gotidus:sha256:outdated", false)
+		viewRows.AddRow("foo_anonymized", fingerprint(fooQuery), false)
+		viewRows.AddRow("stale_anonymized", nil, false)
+
+		mock.
+			ExpectQuery(queryBuilder.ListViewFingerprintsQuery()).
+			WithArgs("", "anonymized").
+			WillReturnRows(viewRows)
+
+		tableRows := sqlmock.NewRows([]string{"schemaname", "tablename"})
+		tableRows.AddRow("public", "bar")
+		tableRows.AddRow("public", "baz")
+		tableRows.AddRow("public", "foo")
+
+		mock.
+			ExpectQuery(queryBuilder.ListTablesQuery()).
+			WithArgs("").
+			WillReturnRows(tableRows)
+
+		for _, tableName := range []string{"bar", "baz", "foo"} {
+			columnRows := newColumnRows()
+			columnRows.AddRow("id", "integer", 1, false, nil, nil, nil, nil, nil, nil)
+
+			mock.
+				ExpectQuery(queryBuilder.ListColumnsQuery()).
+				WithArgs("public", tableName).
+				WillReturnRows(columnRows)
+		}
+	}
+
+	cases := []struct {
+		title          string
+		setupMock      func(sqlmock.Sqlmock)
+		expectedReport *SyncReport
+		expectedError  error
+	}{
+		{
+			title: "view selection fails",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+
+				mock.
+					ExpectQuery(queryBuilder.ListViewFingerprintsQuery()).
+					WithArgs("", "anonymized").
+					WillReturnError(errors.New("simulated failure"))
+
+				mock.ExpectRollback()
+			},
+			expectedError: errors.New("Failed to select views: simulated failure"),
+		},
+		{
+			title: "replacing a view fails",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+
+				setupIntrospection(mock)
+
+				mock.
+					ExpectExec(queryBuilder.DropViewQuery("stale_anonymized")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.
+					ExpectExec(queryBuilder.DropViewQuery("bar_anonymized")).
+					WillReturnError(errors.New("simulated failure"))
+
+				mock.ExpectRollback()
+			},
+			expectedError: errors.New("Failed to drop view 'bar_anonymized': simulated failure"),
+		},
+		{
+			title: "only changed views are touched",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+
+				setupIntrospection(mock)
+
+				mock.
+					ExpectExec(queryBuilder.DropViewQuery("stale_anonymized")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.
+					ExpectExec(queryBuilder.DropViewQuery("bar_anonymized")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.
+					ExpectExec(barQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.
+					ExpectExec(
+						queryBuilder.CommentViewQuery("bar_anonymized", fingerprint(barQuery)),
+					).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.
+					ExpectExec(bazQuery).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.
+					ExpectExec(
+						queryBuilder.CommentViewQuery("baz_anonymized", fingerprint(bazQuery)),
+					).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectCommit()
+			},
+			expectedReport: &SyncReport{
+				Created:   []string{"baz_anonymized"},
+				Replaced:  []string{"bar_anonymized"},
+				Dropped:   []string{"stale_anonymized"},
+				Unchanged: []string{"foo_anonymized"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to initialize DB mock")
+			}
+
+			c.setupMock(dbMock)
+
+			generator := NewGenerator(queryBuilder)
+
+			report, err := generator.SyncViews(context.Background(), db)
+
+			testutils.CompareStructs(err, c.expectedError, t)
+			testutils.CompareStructs(report, c.expectedReport, t)
+
+			if err := dbMock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Did not execute expected queries: %+v", err)
+			}
+		})
+	}
+}
+
+func TestGeneratorPlanSyncWithTableFilters(t *testing.T) {
+	queryBuilder := &mockQueryBuilder{}
+
+	db, dbMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to initialize DB mock")
+	}
+
+	createQuery := "create_view_query:users_anonymized;users;users.id AS id"
+
+	viewRows := sqlmock.NewRows([]string{"relname", "obj_description", "materialized"})
+	viewRows.AddRow("schema_migrations_anonymized", nil, false)
+	viewRows.AddRow("users_anonymized", fingerprint(createQuery), false)
+
+	dbMock.
+		ExpectQuery(queryBuilder.ListViewFingerprintsQuery()).
+		WithArgs("", "anonymized").
+		WillReturnRows(viewRows)
+
+	tableRows := sqlmock.NewRows([]string{"schemaname", "tablename"})
+	tableRows.AddRow("public", "schema_migrations")
+	tableRows.AddRow("public", "users")
+
+	dbMock.
+		ExpectQuery(queryBuilder.ListTablesQuery()).
+		WithArgs("").
+		WillReturnRows(tableRows)
+
+	for _, tableName := range []string{"schema_migrations", "users"} {
+		columnRows := newColumnRows()
+		columnRows.AddRow("id", "integer", 1, false, nil, nil, nil, nil, nil, nil)
+
+		dbMock.
+			ExpectQuery(queryBuilder.ListColumnsQuery()).
+			WithArgs("public", tableName).
+			WillReturnRows(columnRows)
+	}
+
+	generator := NewGenerator(queryBuilder, WithExcludedTables(MatchName("schema_migrations")))
+
+	plan, report, err := generator.PlanSync(context.Background(), db)
+	if err != nil {
+		t.Fatalf("Failed to build plan: %+v", err)
+	}
+
+	expectedStatements := []Statement{
+		{
+			Kind:      StatementDropView,
+			TableName: "schema_migrations",
+			ViewName:  "schema_migrations_anonymized",
+			Query:     "drop_view_query:schema_migrations_anonymized",
+		},
+	}
+
+	testutils.CompareStructs(plan.Statements, expectedStatements, t)
+
+	expectedReport := &SyncReport{
+		Created:   []string{},
+		Replaced:  []string{},
+		Dropped:   []string{"schema_migrations_anonymized"},
+		Unchanged: []string{"users_anonymized"},
+	}
+
+	testutils.CompareStructs(report, expectedReport, t)
+
+	if err := dbMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Did not execute expected queries: %+v", err)
+	}
+}
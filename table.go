@@ -1,5 +1,7 @@
 package gotidus
 
+import "sort"
+
 // NewTable initializes a Table object wich blank columns.
 func NewTable() *Table {
 	table := &Table{
@@ -11,7 +13,23 @@ func NewTable() *Table {
 
 // Table is the type holding the column configuration
 type Table struct {
-	columns map[string]Anonymizer
+	columns         map[string]Anonymizer
+	computedColumns []computedColumn
+	outputNames     map[string]string
+	rowFilters      []RowFilter
+	sampling        RowFilter
+	structureOnly   bool
+	uniqueKey       []string
+
+	unmaskRole        string
+	columnUnmaskRoles map[string]string
+}
+
+// computedColumn is an additional column of the view computed from a column of the table.
+type computedColumn struct {
+	name         string
+	sourceColumn string
+	anonymizer   Anonymizer
 }
 
 // AddAnonymizer allows setting a specific Anonymizer for a column of the given name.
@@ -22,6 +40,132 @@ func (t *Table) AddAnonymizer(columnName string, anonymizer Anonymizer) *Table {
 	return t
 }
 
+// OmitColumn omits the columns of the given names from the view of the table.
+// They are configured with the OmitAnonymizer.
+func (t *Table) OmitColumn(columnNames ...string) *Table {
+	for _, columnName := range columnNames {
+		t.columns[columnName] = NewOmitAnonymizer()
+	}
+
+	return t
+}
+
+// AddComputedColumn adds a column of the given name to the view, which is computed
+// by the Anonymizer from the source column, e.g. an age bucket from the date of birth.
+// Computed columns are added after the columns of the table in the order they were added.
+// The source column itself is still part of the view unless it is omitted with OmitColumn.
+func (t *Table) AddComputedColumn(name string, sourceColumn string, anonymizer Anonymizer) *Table {
+	t.computedColumns = append(
+		t.computedColumns,
+		computedColumn{
+			name:         name,
+			sourceColumn: sourceColumn,
+			anonymizer:   anonymizer,
+		},
+	)
+
+	return t
+}
+
+// RenameColumn exposes the column of the given name as outputName in the view.
+// If a column was previously renamed, the name will be overwritten.
+func (t *Table) RenameColumn(columnName string, outputName string) *Table {
+	if t.outputNames == nil {
+		t.outputNames = make(map[string]string)
+	}
+
+	t.outputNames[columnName] = outputName
+
+	return t
+}
+
+// outputName returns the name the column of the given name is exposed as in the view.
+func (t *Table) outputName(columnName string) string {
+	if outputName, ok := t.outputNames[columnName]; ok {
+		return outputName
+	}
+
+	return columnName
+}
+
+// WithRowFilter restricts the rows exposed by the view of the table to those matching
+// all given filters. Repeated calls add further filters.
+func (t *Table) WithRowFilter(filters ...RowFilter) *Table {
+	t.rowFilters = append(t.rowFilters, filters...)
+
+	return t
+}
+
+// WithSampling restricts the rows exposed by the view of the table to a sample
+// selected by the given sampler, e.g. a postgres.HashSampler.
+// The sampler is combined with the row filters of the table.
+// If a sampler was previously configured, it will be overwritten.
+func (t *Table) WithSampling(sampler RowFilter) *Table {
+	t.sampling = sampler
+
+	return t
+}
+
+// conditions returns the row filters and the sampler of the table.
+func (t *Table) conditions() []RowFilter {
+	if t.sampling == nil {
+		return t.rowFilters
+	}
+
+	return append(append([]RowFilter{}, t.rowFilters...), t.sampling)
+}
+
+// StructureOnly makes the view of the table expose the columns of the table without any rows.
+// This allows restoring the structure of tables whose data must never leave the database.
+func (t *Table) StructureOnly() *Table {
+	t.structureOnly = true
+
+	return t
+}
+
+// WithUniqueKey configures the columns of the view which uniquely identify its rows.
+// For materialized views, a unique index is created on the columns, which is required
+// for refreshing the view concurrently. It has no effect on views that are not materialized.
+// Renamed columns are referenced by their name within the view.
+func (t *Table) WithUniqueKey(columnNames ...string) *Table {
+	t.uniqueKey = columnNames
+
+	return t
+}
+
+// WithUnmaskRole allows members of the given role to see the original values of all anonymized
+// columns of the table. It takes precedence over the role configured through WithUnmaskRole
+// on the Generator.
+func (t *Table) WithUnmaskRole(role string) *Table {
+	t.unmaskRole = role
+
+	return t
+}
+
+// WithColumnUnmaskRole allows members of the given role to see the original values
+// of the column of the given name. It takes precedence over the roles configured for
+// the table and the Generator. An empty role disables unmasking for the column.
+func (t *Table) WithColumnUnmaskRole(columnName string, role string) *Table {
+	if t.columnUnmaskRoles == nil {
+		t.columnUnmaskRoles = make(map[string]string)
+	}
+
+	t.columnUnmaskRoles[columnName] = role
+
+	return t
+}
+
+// Allow marks the columns of the given names as safe to be exposed as they are.
+// They are configured with the PassthroughAnonymizer, so that they are not replaced
+// by the default anonymizer configured with WithDefaultAnonymizer.
+func (t *Table) Allow(columnNames ...string) *Table {
+	for _, columnName := range columnNames {
+		t.columns[columnName] = NewPassthroughAnonymizer()
+	}
+
+	return t
+}
+
 // GetAnonymizer retrieves an Anonymizer from the Table configuration.
 // If an Anonymizer was configured for the given name, that Anonymizer will be returned.
 // If no Anonymizer was configured for the given name, the NoopAnonymizer will be returned.
@@ -33,3 +177,41 @@ func (t *Table) GetAnonymizer(columnName string) Anonymizer {
 
 	return NewNoopAnonymizer()
 }
+
+// getAnonymizer retrieves the Anonymizer configured for the column of the given name
+// and reports whether one was configured.
+func (t *Table) getAnonymizer(columnName string) (Anonymizer, bool) {
+	anonymizer, ok := t.columns[columnName]
+
+	return anonymizer, ok
+}
+
+// columnNames returns the sorted names of all columns of the table referenced by the configuration,
+// which includes the source columns of computed columns, renamed columns and columns with unmask roles.
+// The columns of the unique key are not included, as they are named within the view.
+func (t *Table) columnNames() []string {
+	referenced := make(map[string]bool, len(t.columns))
+	for name := range t.columns {
+		referenced[name] = true
+	}
+
+	for _, column := range t.computedColumns {
+		referenced[column.sourceColumn] = true
+	}
+
+	for name := range t.outputNames {
+		referenced[name] = true
+	}
+
+	for name := range t.columnUnmaskRoles {
+		referenced[name] = true
+	}
+
+	names := make([]string, 0, len(referenced))
+	for name := range referenced {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -37,3 +37,96 @@ func TestTableAddAndGetAnonymizer(t *testing.T) {
 
 	testutils.CompareStructs(defaultAnon, NewNoopAnonymizer(), t)
 }
+
+func TestTableAllow(t *testing.T) {
+	table := NewTable().Allow("foo", "bar")
+
+	testutils.CompareStructs(table.GetAnonymizer("foo"), NewPassthroughAnonymizer(), t)
+	testutils.CompareStructs(table.GetAnonymizer("bar"), NewPassthroughAnonymizer(), t)
+
+	_, ok := table.getAnonymizer("baz")
+	if ok {
+		t.Errorf("Expected no anonymizer to be configured for baz")
+	}
+}
+
+func TestTableWithRowFilter(t *testing.T) {
+	first := NewSQLRowFilter("deleted_at IS NULL")
+	second := NewSQLRowFilter("NOT internal")
+
+	table := NewTable().WithRowFilter(first).WithRowFilter(second)
+
+	testutils.CompareStructs(table.rowFilters, []RowFilter{first, second}, t)
+}
+
+func TestTableWithSampling(t *testing.T) {
+	filter := NewSQLRowFilter("deleted_at IS NULL")
+	sampler := NewSQLRowFilter("id % 10 = 0")
+
+	table := NewTable().WithRowFilter(filter)
+
+	testutils.CompareStructs(table.conditions(), []RowFilter{filter}, t)
+
+	table.WithSampling(sampler)
+
+	testutils.CompareStructs(table.conditions(), []RowFilter{filter, sampler}, t)
+	testutils.CompareStructs(table.rowFilters, []RowFilter{filter}, t)
+}
+
+func TestTableOmitColumn(t *testing.T) {
+	table := NewTable().OmitColumn("scan", "secret")
+
+	testutils.CompareStructs(table.GetAnonymizer("scan"), NewOmitAnonymizer(), t)
+	testutils.CompareStructs(table.GetAnonymizer("secret"), NewOmitAnonymizer(), t)
+}
+
+func TestTableAddComputedColumn(t *testing.T) {
+	anonymizer := NewExpressionAnonymizer("SPLIT_PART({column}, '@', 2)")
+
+	table := NewTable().AddComputedColumn("email_domain", "email", anonymizer)
+
+	testutils.CompareStructs(
+		table.computedColumns,
+		[]computedColumn{{name: "email_domain", sourceColumn: "email", anonymizer: anonymizer}},
+		t,
+	)
+}
+
+func TestTableRenameColumn(t *testing.T) {
+	table := NewTable().RenameColumn("dob", "date_of_birth")
+
+	testutils.CompareStrings(table.outputName("dob"), "date_of_birth", t)
+	testutils.CompareStrings(table.outputName("email"), "email", t)
+}
+
+func TestTableColumnNames(t *testing.T) {
+	table := NewTable().
+		AddAnonymizer("iban", NewNoopAnonymizer()).
+		AddComputedColumn("email_domain", "email", NewNoopAnonymizer()).
+		RenameColumn("dob", "date_of_birth")
+
+	testutils.CompareStructs(table.columnNames(), []string{"dob", "email", "iban"}, t)
+}
+
+func TestTableStructureOnly(t *testing.T) {
+	table := NewTable()
+
+	testutils.CompareStructs(table.structureOnly, false, t)
+
+	table.StructureOnly()
+
+	testutils.CompareStructs(table.structureOnly, true, t)
+}
+
+func TestTableWithUnmaskRoles(t *testing.T) {
+	table := NewTable().WithUnmaskRole("admin").WithColumnUnmaskRole("iban", "finance")
+
+	testutils.CompareStrings(table.unmaskRole, "admin", t)
+	testutils.CompareStructs(table.columnUnmaskRoles, map[string]string{"iban": "finance"}, t)
+}
+
+func TestTableWithUniqueKey(t *testing.T) {
+	table := NewTable().WithUniqueKey("tenant_id", "id")
+
+	testutils.CompareStructs(table.uniqueKey, []string{"tenant_id", "id"}, t)
+}